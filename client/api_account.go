@@ -2,6 +2,7 @@ package client
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/bnb-chain/greenfield-go-sdk/types"
 
@@ -21,7 +22,9 @@ type Account interface {
 	GetModuleAccountByName(ctx context.Context, name string) (authTypes.ModuleAccountI, error)
 	GetPaymentAccountsByOwner(ctx context.Context, owner string) ([]*paymentTypes.PaymentAccount, error)
 
-	CreatePaymentAccount(ctx context.Context, address string, txOption gnfdSdkTypes.TxOption) (string, error)
+	// CreatePaymentAccount creates a payment account owned by the address,
+	// it returns the HEX-encoded address of the new payment account and the txn hash
+	CreatePaymentAccount(ctx context.Context, address string, txOption gnfdSdkTypes.TxOption) (string, string, error)
 	Transfer(ctx context.Context, toAddress string, amount math.Int, txOption gnfdSdkTypes.TxOption) (string, error)
 	MultiTransfer(ctx context.Context, details []types.TransferDetail, txOption gnfdSdkTypes.TxOption) (string, error)
 }
@@ -53,18 +56,35 @@ func (c *client) GetAccount(ctx context.Context, address string) (authTypes.Acco
 }
 
 // CreatePaymentAccount creates a new payment account on the blockchain using the provided address.
-// It returns a TxResponse containing information about the transaction, or an error if the transaction failed.
-func (c *client) CreatePaymentAccount(ctx context.Context, address string, txOption gnfdSdkTypes.TxOption) (string, error) {
+// It waits for the transaction up to DefaultWaitTxTimeout or the deadline of ctx, and returns the address of the new
+// payment account parsed from the tx events with the txn hash.
+func (c *client) CreatePaymentAccount(ctx context.Context, address string, txOption gnfdSdkTypes.TxOption) (string, string, error) {
 	accAddress, err := sdk.AccAddressFromHexUnsafe(address)
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
 	msgCreatePaymentAccount := paymentTypes.NewMsgCreatePaymentAccount(accAddress.String())
 	tx, err := c.chainClient.BroadcastTx(ctx, []sdk.Msg{msgCreatePaymentAccount}, &txOption)
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
-	return tx.TxResponse.TxHash, nil
+	txHash := tx.TxResponse.TxHash
+	waitCtx, cancel := context.WithTimeout(ctx, types.DefaultWaitTxTimeout)
+	defer cancel()
+	waitForTx, err := c.WaitForTx(waitCtx, txHash)
+	if err != nil {
+		return "", txHash, err
+	}
+	if waitForTx.Code != 0 {
+		return "", txHash, fmt.Errorf("fail to create payment account: %s", waitForTx.RawLog)
+	}
+
+	// the address of the payment account is derived on chain, it is emitted by EventPaymentAccountUpdate
+	attributes, ok := getEventAttributes(waitForTx, "EventPaymentAccountUpdate")
+	if ok && attributes["addr"] != "" {
+		return attributes["addr"], txHash, nil
+	}
+	return "", txHash, types.ErrorPaymentAccountNotFound
 }
 
 func (c *client) GetModuleAccountByName(ctx context.Context, name string) (authTypes.ModuleAccountI, error) {
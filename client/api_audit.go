@@ -0,0 +1,203 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+
+	gnfdsdk "github.com/bnb-chain/greenfield/sdk/types"
+	storageTypes "github.com/bnb-chain/greenfield/x/storage/types"
+	"github.com/cosmos/cosmos-sdk/types/tx"
+	"github.com/rs/zerolog/log"
+
+	"github.com/bnb-chain/greenfield-go-sdk/types"
+)
+
+type Audit interface {
+	// AuditBucket samples the sealed objects of the bucket, challenges random pieces of them on the primary SP and
+	// the secondary SPs, and verifies the responses locally against the checksums on chain.
+	// The failed pieces can be challenged on chain by the SubmitChallenge option.
+	AuditBucket(ctx context.Context, bucketName string, opts types.AuditBucketOptions) (*types.BucketAuditReport, error)
+}
+
+// auditTarget indicates the piece to challenge
+type auditTarget struct {
+	objectInfo      *storageTypes.ObjectInfo
+	spAddr          string
+	segmentIndex    int
+	redundancyIndex int
+}
+
+// AuditBucket audits the sampled pieces of the bucket and returns the audit report, the failed challenges are reported
+// per SP instead of returning an error
+func (c *client) AuditBucket(ctx context.Context, bucketName string, opts types.AuditBucketOptions) (*types.BucketAuditReport, error) {
+	bucketInfo, err := c.HeadBucket(ctx, bucketName)
+	if err != nil {
+		return nil, err
+	}
+	_, _, segmentSize, err := c.GetRedundancyParams()
+	if err != nil {
+		return nil, err
+	}
+
+	sampleSize := opts.SampleSize
+	if sampleSize <= 0 {
+		sampleSize = types.DefaultAuditSampleSize
+	}
+	// set the default txn broadcast mode as block mode
+	if opts.TxOpts == nil {
+		broadcastMode := tx.BroadcastMode_BROADCAST_MODE_BLOCK
+		opts.TxOpts = &gnfdsdk.TxOption{Mode: &broadcastMode}
+	}
+
+	listResult, err := c.ListObjects(ctx, bucketName, types.ListObjectsOptions{})
+	if err != nil {
+		return nil, err
+	}
+	objectNames := make([]string, 0, len(listResult.Objects))
+	for _, object := range listResult.Objects {
+		// only the sealed objects with payload can be challenged
+		if object.Removed || object.ObjectInfo == nil || object.ObjectInfo.PayloadSize == 0 ||
+			object.ObjectInfo.ObjectStatus != storageTypes.OBJECT_STATUS_SEALED {
+			continue
+		}
+		objectNames = append(objectNames, object.ObjectInfo.ObjectName)
+	}
+
+	random := rand.New(rand.NewSource(time.Now().UnixNano()))
+	random.Shuffle(len(objectNames), func(i, j int) {
+		objectNames[i], objectNames[j] = objectNames[j], objectNames[i]
+	})
+	if len(objectNames) > sampleSize {
+		objectNames = objectNames[:sampleSize]
+	}
+
+	report := &types.BucketAuditReport{
+		BucketName: bucketName,
+		StartTime:  time.Now(),
+		SPResults:  make(map[string]*types.SPAuditResult),
+	}
+	defer func() {
+		report.EndTime = time.Now()
+	}()
+
+	for _, objectName := range objectNames {
+		if ctx.Err() != nil {
+			return report, ctx.Err()
+		}
+		// the checksums and the secondary SPs on chain are used to verify the challenge
+		objectInfo, err := c.HeadObject(ctx, bucketName, objectName)
+		if err != nil {
+			log.Error().Msg(fmt.Sprintf("fail to head object %s for audit: %s", objectName, err.Error()))
+			continue
+		}
+		report.SampledObjects = append(report.SampledObjects, objectName)
+
+		for _, target := range sampleAuditTargets(random, objectInfo, bucketInfo.PrimarySpAddress, segmentSize,
+			opts.ChallengesPerObject) {
+			c.auditPiece(ctx, report, target, opts)
+		}
+	}
+	return report, nil
+}
+
+// sampleAuditTargets picks the redundancy indexes to challenge and a random segment for each of them,
+// the redundancy index of the primary SP is -1
+func sampleAuditTargets(random *rand.Rand, objectInfo *storageTypes.ObjectInfo, primarySPAddr string, segmentSize uint64,
+	challengesPerObject int,
+) []auditTarget {
+	redundancyIndexes := make([]int, 0, len(objectInfo.SecondarySpAddresses)+1)
+	for i := -1; i < len(objectInfo.SecondarySpAddresses); i++ {
+		redundancyIndexes = append(redundancyIndexes, i)
+	}
+	random.Shuffle(len(redundancyIndexes), func(i, j int) {
+		redundancyIndexes[i], redundancyIndexes[j] = redundancyIndexes[j], redundancyIndexes[i]
+	})
+	if challengesPerObject > 0 && len(redundancyIndexes) > challengesPerObject {
+		redundancyIndexes = redundancyIndexes[:challengesPerObject]
+	}
+
+	segmentCount := int((objectInfo.PayloadSize + segmentSize - 1) / segmentSize)
+	targets := make([]auditTarget, 0, len(redundancyIndexes))
+	for _, redundancyIndex := range redundancyIndexes {
+		spAddr := primarySPAddr
+		if redundancyIndex >= 0 {
+			spAddr = objectInfo.SecondarySpAddresses[redundancyIndex]
+		}
+		targets = append(targets, auditTarget{
+			objectInfo:      objectInfo,
+			spAddr:          spAddr,
+			segmentIndex:    random.Intn(segmentCount),
+			redundancyIndex: redundancyIndex,
+		})
+	}
+	return targets
+}
+
+// auditPiece challenges the piece on the SP storing it and records the result to the report
+func (c *client) auditPiece(ctx context.Context, report *types.BucketAuditReport, target auditTarget, opts types.AuditBucketOptions) {
+	objectInfo := target.objectInfo
+	spResult, ok := report.SPResults[target.spAddr]
+	if !ok {
+		spResult = &types.SPAuditResult{SPAddress: target.spAddr}
+		report.SPResults[target.spAddr] = spResult
+	}
+	report.Challenges++
+	spResult.Challenges++
+
+	info := types.ChallengeInfo{
+		ObjectId:        objectInfo.Id.String(),
+		PieceIndex:      target.segmentIndex,
+		RedundancyIndex: target.redundancyIndex,
+	}
+	failure := types.AuditFailure{
+		ObjectName:      objectInfo.ObjectName,
+		ObjectId:        info.ObjectId,
+		SPAddress:       target.spAddr,
+		SegmentIndex:    target.segmentIndex,
+		RedundancyIndex: target.redundancyIndex,
+	}
+
+	verdict, err := c.challengePiece(ctx, info, objectInfo, target.spAddr)
+	if err != nil {
+		failure.Reason = err.Error()
+		spResult.Failures = append(spResult.Failures, failure)
+		return
+	}
+	if verdict.Valid {
+		report.Passed++
+		spResult.Passed++
+		return
+	}
+
+	failure.Reason = verdict.Reason
+	failure.Verdict = verdict
+	// only the pieces proven to be corrupted are challenged on chain, the request errors may be caused by the network
+	if opts.SubmitChallenge {
+		resp, err := c.SubmitChallenge(ctx, c.MustGetDefaultAccount().GetAddress().String(), target.spAddr,
+			objectInfo.BucketName, objectInfo.ObjectName, false, uint32(target.segmentIndex), *opts.TxOpts)
+		if err != nil {
+			log.Error().Msg(fmt.Sprintf("fail to submit challenge of object %s to SP %s: %s", objectInfo.ObjectName,
+				target.spAddr, err.Error()))
+		} else {
+			failure.ChallengeTxnHash = resp.TxHash
+		}
+	}
+	spResult.Failures = append(spResult.Failures, failure)
+}
+
+// challengePiece requests the challenge info from the SP and verifies it
+func (c *client) challengePiece(ctx context.Context, info types.ChallengeInfo, objectInfo *storageTypes.ObjectInfo,
+	spAddr string,
+) (*types.ChallengeVerdict, error) {
+	endpoint, err := c.getSPUrlByAddr(spAddr)
+	if err != nil {
+		return nil, err
+	}
+	result, err := c.getChallengeInfoFromSP(ctx, info, endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("fail to get challenge info: %s", err.Error())
+	}
+	return c.VerifyChallengeResult(result, objectInfo, info)
+}
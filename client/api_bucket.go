@@ -23,6 +23,8 @@ import (
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/cosmos/cosmos-sdk/types/tx"
 	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	"github.com/bnb-chain/greenfield-go-sdk/pkg/utils"
 	"github.com/bnb-chain/greenfield-go-sdk/types"
@@ -31,7 +33,8 @@ import (
 type Bucket interface {
 	GetCreateBucketApproval(ctx context.Context, createBucketMsg *storageTypes.MsgCreateBucket) (*storageTypes.MsgCreateBucket, error)
 	// CreateBucket get approval of creating bucket and send createBucket txn to greenfield chain
-	// primaryAddr indicates the HEX-encoded string of the primary storage provider address to which the bucket will be created
+	// primaryAddr indicates the HEX-encoded string of the primary storage provider address to which the bucket will be created,
+	// the primary SP is chosen by the SP selector of the client if it is empty
 	CreateBucket(ctx context.Context, bucketName string, primaryAddr string, opts types.CreateBucketOptions) (string, error)
 	DeleteBucket(ctx context.Context, bucketName string, opt types.DeleteBucketOption) (string, error)
 
@@ -54,6 +57,12 @@ type Bucket interface {
 	IsBucketPermissionAllowed(ctx context.Context, userAddr string, bucketName string, action permTypes.ActionType) (permTypes.Effect, error)
 
 	ListBuckets(ctx context.Context) (types.ListBucketsResult, error)
+	// ListBucketsByPaymentAccount lists the buckets which are billed to the payment account.
+	// paymentAddr indicates the HEX-encoded string of the payment account or the owner account itself
+	ListBucketsByPaymentAccount(ctx context.Context, paymentAddr string) (types.ListBucketsResult, error)
+	// RebindBuckets updates the payment address of the buckets in batched transactions and returns the txn hashes.
+	// paymentAddr indicates the HEX-encoded string of the new payment address
+	RebindBuckets(ctx context.Context, bucketNames []string, paymentAddr string, opt types.RebindBucketsOption) ([]string, error)
 	ListBucketReadRecord(ctx context.Context, bucketName string, opts types.ListReadRecordOptions) (types.QuotaRecordInfo, error)
 
 	BuyQuotaForBucket(ctx context.Context, bucketName string, targetQuota uint64, opt types.BuyQuotaOption) (string, error)
@@ -111,6 +120,14 @@ func (c *client) GetCreateBucketApproval(ctx context.Context, createBucketMsg *s
 
 // CreateBucket get approval of creating bucket and send createBucket txn to greenfield chain
 func (c *client) CreateBucket(ctx context.Context, bucketName string, primaryAddr string, opts types.CreateBucketOptions) (string, error) {
+	if primaryAddr == "" {
+		spList, err := c.selectSPs(ctx, 1)
+		if err != nil {
+			return "", fmt.Errorf("fail to select the primary SP: %s", err.Error())
+		}
+		primaryAddr = spList[0].OperatorAddress
+	}
+
 	address, err := sdk.AccAddressFromHexUnsafe(primaryAddr)
 	if err != nil {
 		return "", err
@@ -351,9 +368,105 @@ func (c *client) GetBucketPolicy(ctx context.Context, bucketName string, princip
 
 // ListBuckets list buckets for the owner
 func (c *client) ListBuckets(ctx context.Context) (types.ListBucketsResult, error) {
+	return c.listBucketsOfUser(ctx, c.MustGetDefaultAccount().GetAddress().String())
+}
+
+// ListBucketsByPaymentAccount lists the buckets of the payment account owner and keeps the ones billed to the payment account
+func (c *client) ListBucketsByPaymentAccount(ctx context.Context, paymentAddr string) (types.ListBucketsResult, error) {
+	paymentAcc, err := sdk.AccAddressFromHexUnsafe(paymentAddr)
+	if err != nil {
+		return types.ListBucketsResult{}, err
+	}
+
+	// the bucket can only be billed to the owner account or the payment accounts of the owner
+	owner := paymentAcc.String()
+	paymentAccount, err := c.GetPaymentAccount(ctx, paymentAddr)
+	// the address is not a payment account if it is not found, it may be the owner account itself
+	if err != nil && status.Code(err) != codes.NotFound {
+		return types.ListBucketsResult{}, err
+	}
+	if err == nil {
+		ownerAcc, err := sdk.AccAddressFromHexUnsafe(paymentAccount.Owner)
+		if err != nil {
+			return types.ListBucketsResult{}, err
+		}
+		owner = ownerAcc.String()
+	}
+
+	listResult, err := c.listBucketsOfUser(ctx, owner)
+	if err != nil {
+		return types.ListBucketsResult{}, err
+	}
+
+	buckets := make([]*types.BucketMeta, 0)
+	for _, bucket := range listResult.Buckets {
+		if bucket.Removed || bucket.BucketInfo == nil || !strings.EqualFold(bucket.BucketInfo.PaymentAddress, paymentAcc.String()) {
+			continue
+		}
+		buckets = append(buckets, bucket)
+	}
+	return types.ListBucketsResult{Buckets: buckets}, nil
+}
+
+// RebindBuckets sends the update bucket info msgs which set the payment address of the buckets in batched transactions,
+// it returns the txn hashes of the sent transactions if any batch fails
+func (c *client) RebindBuckets(ctx context.Context, bucketNames []string, paymentAddr string, opt types.RebindBucketsOption) ([]string, error) {
+	if len(bucketNames) == 0 {
+		return nil, nil
+	}
+	paymentAcc, err := sdk.AccAddressFromHexUnsafe(paymentAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	batchSize := opt.BatchSize
+	if batchSize <= 0 {
+		batchSize = types.DefaultRebindBatchSize
+	}
+
+	// set the default txn broadcast mode as block mode
+	if opt.TxOpts == nil {
+		broadcastMode := tx.BroadcastMode_BROADCAST_MODE_BLOCK
+		opt.TxOpts = &gnfdsdk.TxOption{Mode: &broadcastMode}
+	}
+
+	operator := c.MustGetDefaultAccount().GetAddress()
+	msgs := make([]sdk.Msg, 0, len(bucketNames))
+	for _, bucketName := range bucketNames {
+		// the read quota and visibility are kept as they are
+		bucketInfo, err := c.HeadBucket(ctx, bucketName)
+		if err != nil {
+			return nil, fmt.Errorf("fail to head bucket %s: %s", bucketName, err.Error())
+		}
+		msg := storageTypes.NewMsgUpdateBucketInfo(operator, bucketName, &bucketInfo.ChargedReadQuota, paymentAcc, bucketInfo.Visibility)
+		if err = msg.ValidateBasic(); err != nil {
+			return nil, fmt.Errorf("invalid update msg of bucket %s: %s", bucketName, err.Error())
+		}
+		msgs = append(msgs, msg)
+	}
+
+	txnHashes := make([]string, 0, (len(msgs)+batchSize-1)/batchSize)
+	for start := 0; start < len(msgs); start += batchSize {
+		end := start + batchSize
+		if end > len(msgs) {
+			end = len(msgs)
+		}
+
+		resp, err := c.chainClient.BroadcastTx(ctx, msgs[start:end], opt.TxOpts)
+		if err != nil {
+			return txnHashes, err
+		}
+		txnHashes = append(txnHashes, resp.TxResponse.TxHash)
+	}
+
+	return txnHashes, nil
+}
+
+// listBucketsOfUser lists the buckets owned by the user from SP, userAddr indicates the bech32 string of the user address
+func (c *client) listBucketsOfUser(ctx context.Context, userAddr string) (types.ListBucketsResult, error) {
 	reqMeta := requestMeta{
 		contentSHA256: types.EmptyStringSHA256,
-		userAddress:   c.MustGetDefaultAccount().GetAddress().String(),
+		userAddress:   userAddr,
 	}
 
 	sendOpt := sendOptions{
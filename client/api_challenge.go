@@ -1,15 +1,21 @@
 package client
 
 import (
+	"bytes"
 	"context"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
 	"strings"
 
 	"cosmossdk.io/math"
+	hashlib "github.com/bnb-chain/greenfield-common/go/hash"
 	gnfdsdktypes "github.com/bnb-chain/greenfield/sdk/types"
 	challengetypes "github.com/bnb-chain/greenfield/x/challenge/types"
+	storageTypes "github.com/bnb-chain/greenfield/x/storage/types"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/rs/zerolog/log"
 
@@ -19,11 +25,25 @@ import (
 
 type Challenge interface {
 	GetChallengeInfo(ctx context.Context, info types.ChallengeInfo) (types.ChallengeResult, error)
+	// VerifyChallengeResult reads and closes the piece data of the challenge result, and verifies the piece data,
+	// the piece hashes and the integrity hash against the checksum of the object on chain
+	VerifyChallengeResult(result types.ChallengeResult, objectInfo *storageTypes.ObjectInfo, info types.ChallengeInfo) (*types.ChallengeVerdict, error)
 	SubmitChallenge(ctx context.Context, challengerAddress, spOperatorAddress, bucketName, objectName string, randomIndex bool, segmentIndex uint32, txOption gnfdsdktypes.TxOption) (*sdk.TxResponse, error)
 	AttestChallenge(ctx context.Context, submitterAddress, challengerAddress, spOperatorAddress string, challengeId uint64, objectId math.Uint, voteResult challengetypes.VoteResult, voteValidatorSet []uint64, VoteAggSignature []byte, txOption gnfdsdktypes.TxOption) (*sdk.TxResponse, error)
 	LatestAttestedChallenges(ctx context.Context, req *challengetypes.QueryLatestAttestedChallengesRequest) ([]uint64, error)
 	InturnAttestationSubmitter(ctx context.Context, req *challengetypes.QueryInturnAttestationSubmitterRequest) (*challengetypes.QueryInturnAttestationSubmitterResponse, error)
 	ChallengeParams(ctx context.Context, req *challengetypes.QueryParamsRequest) (*challengetypes.QueryParamsResponse, error)
+
+	// NewChallengeTracker returns the tracker which submits the challenges and polls their attestation
+	NewChallengeTracker(opts types.ChallengeTrackerOptions) *ChallengeTracker
+	// IsChallengeAttested checks whether the challenge is in the latest attested challenges
+	IsChallengeAttested(ctx context.Context, challengeId uint64) (bool, error)
+	// IsInturnAttestationSubmitter checks whether the validator of the hex-encoded bls public key is the in-turn
+	// attestation submitter, it returns the submit interval of the in-turn submitter
+	IsInturnAttestationSubmitter(ctx context.Context, blsPubKey string) (bool, *challengetypes.SubmitInterval, error)
+	// AttestChallengeInturn attests the challenge if it is not attested yet and the validator of the bls public key is
+	// the in-turn submitter, it returns ErrorChallengeAttested or ErrorNotInturnSubmitter otherwise
+	AttestChallengeInturn(ctx context.Context, blsPubKey string, attestation types.ChallengeAttestation, txOption gnfdsdktypes.TxOption) (*sdk.TxResponse, error)
 }
 
 // GetChallengeInfo  sends request to challenge and get challenge result info
@@ -41,18 +61,6 @@ func (c *client) GetChallengeInfo(ctx context.Context, info types.ChallengeInfo)
 		return types.ChallengeResult{}, errors.New("redundancy index error ")
 	}
 
-	reqMeta := requestMeta{
-		urlRelPath:    types.ChallengeUrl,
-		contentSHA256: types.EmptyStringSHA256,
-		challengeInfo: info,
-	}
-
-	sendOpt := sendOptions{
-		method:           http.MethodGet,
-		isAdminApi:       true,
-		disableCloseBody: true,
-	}
-
 	objectInfo, err := c.HeadObjectByID(ctx, info.ObjectId)
 	if err != nil {
 		return types.ChallengeResult{}, err
@@ -65,6 +73,23 @@ func (c *client) GetChallengeInfo(ctx context.Context, info types.ChallengeInfo)
 		return types.ChallengeResult{}, err
 	}
 
+	return c.getChallengeInfoFromSP(ctx, info, endpoint)
+}
+
+// getChallengeInfoFromSP sends the challenge request to the SP endpoint, it is used to challenge the secondary SPs directly
+func (c *client) getChallengeInfoFromSP(ctx context.Context, info types.ChallengeInfo, endpoint *url.URL) (types.ChallengeResult, error) {
+	reqMeta := requestMeta{
+		urlRelPath:    types.ChallengeUrl,
+		contentSHA256: types.EmptyStringSHA256,
+		challengeInfo: info,
+	}
+
+	sendOpt := sendOptions{
+		method:           http.MethodGet,
+		isAdminApi:       true,
+		disableCloseBody: true,
+	}
+
 	resp, err := c.sendReq(ctx, reqMeta, &sendOpt, endpoint)
 	if err != nil {
 		return types.ChallengeResult{}, err
@@ -83,6 +108,7 @@ func (c *client) GetChallengeInfo(ctx context.Context, info types.ChallengeInfo)
 	hashList := strings.Split(pieceHashes, ",")
 	// min hash num equals one segment hash plus EC dataShards, parityShards
 	if len(hashList) < 1 {
+		utils.CloseResponse(resp)
 		return types.ChallengeResult{}, errors.New("get piece hashes less than 1")
 	}
 
@@ -94,6 +120,74 @@ func (c *client) GetChallengeInfo(ctx context.Context, info types.ChallengeInfo)
 	return result, nil
 }
 
+// VerifyChallengeResult checks the challenge result in the same way as the challenge attestation,
+// the mismatches are reported by the verdict and the error is returned only if the verification can not be done
+func (c *client) VerifyChallengeResult(result types.ChallengeResult, objectInfo *storageTypes.ObjectInfo,
+	info types.ChallengeInfo,
+) (*types.ChallengeVerdict, error) {
+	if result.PieceData == nil {
+		return nil, errors.New("the piece data of the challenge result is nil")
+	}
+	defer result.PieceData.Close()
+	if objectInfo == nil {
+		return nil, errors.New("the object info is nil")
+	}
+	// the first checksum belongs to the primary SP whose redundancy index is -1
+	checksumIndex := info.RedundancyIndex + 1
+	if checksumIndex < 0 || checksumIndex >= len(objectInfo.Checksums) {
+		return nil, fmt.Errorf("the redundancy index %d is out of the range of the %d checksums", info.RedundancyIndex,
+			len(objectInfo.Checksums))
+	}
+	pieceData, err := io.ReadAll(result.PieceData)
+	if err != nil {
+		return nil, fmt.Errorf("fail to read the piece data: %s", err.Error())
+	}
+
+	verdict := &types.ChallengeVerdict{
+		ObjectId:         info.ObjectId,
+		PieceIndex:       info.PieceIndex,
+		RedundancyIndex:  info.RedundancyIndex,
+		ExpectedChecksum: hex.EncodeToString(objectInfo.Checksums[checksumIndex]),
+	}
+
+	pieceHash := hashlib.GenerateChecksum(pieceData)
+	verdict.ComputedPieceHash = hex.EncodeToString(pieceHash)
+
+	pieceHashes := make([][]byte, 0, len(result.PiecesHash))
+	for i, hashStr := range result.PiecesHash {
+		pieceHashBytes, err := hex.DecodeString(strings.TrimSpace(hashStr))
+		if err != nil {
+			verdict.Reason = fmt.Sprintf("the piece hash %d is not hex encoded: %s", i, err.Error())
+			return verdict, nil
+		}
+		pieceHashes = append(pieceHashes, pieceHashBytes)
+	}
+
+	if info.PieceIndex < 0 || info.PieceIndex >= len(pieceHashes) {
+		verdict.Reason = fmt.Sprintf("the piece index %d is out of the range of the %d piece hashes", info.PieceIndex, len(pieceHashes))
+	} else {
+		verdict.PieceHashMatched = bytes.Equal(pieceHash, pieceHashes[info.PieceIndex])
+	}
+
+	integrityHash := hashlib.GenerateIntegrityHash(pieceHashes)
+	verdict.ComputedIntegrityHash = hex.EncodeToString(integrityHash)
+	verdict.IntegrityHashMatched = strings.EqualFold(verdict.ComputedIntegrityHash, strings.TrimSpace(result.IntegrityHash))
+	verdict.ChecksumMatched = strings.EqualFold(verdict.ExpectedChecksum, strings.TrimSpace(result.IntegrityHash))
+
+	switch {
+	case verdict.Reason != "":
+	case !verdict.PieceHashMatched:
+		verdict.Reason = "the piece data does not match the piece hash"
+	case !verdict.IntegrityHashMatched:
+		verdict.Reason = "the piece hashes do not match the integrity hash"
+	case !verdict.ChecksumMatched:
+		verdict.Reason = "the integrity hash does not match the checksum on chain"
+	default:
+		verdict.Valid = true
+	}
+	return verdict, nil
+}
+
 // SubmitChallenge challenges the service provider data integrity, used by off-chain service greenfield-challenger.
 func (c *client) SubmitChallenge(ctx context.Context, challengerAddress, spOperatorAddress, bucketName, objectName string, randomIndex bool, segmentIndex uint32, txOption gnfdsdktypes.TxOption) (*sdk.TxResponse, error) {
 	challenger, err := sdk.AccAddressFromHexUnsafe(challengerAddress)
@@ -154,6 +248,58 @@ func (c *client) InturnAttestationSubmitter(ctx context.Context, req *challenget
 	return c.chainClient.InturnAttestationSubmitter(ctx, req)
 }
 
+// IsChallengeAttested checks the challenge ID against the latest attested challenges on chain
+func (c *client) IsChallengeAttested(ctx context.Context, challengeId uint64) (bool, error) {
+	attestedIds, err := c.LatestAttestedChallenges(ctx, &challengetypes.QueryLatestAttestedChallengesRequest{})
+	if err != nil {
+		return false, err
+	}
+	for _, id := range attestedIds {
+		if id == challengeId {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// IsInturnAttestationSubmitter compares the bls public key with the one of the in-turn attestation submitter
+func (c *client) IsInturnAttestationSubmitter(ctx context.Context, blsPubKey string) (bool, *challengetypes.SubmitInterval, error) {
+	resp, err := c.InturnAttestationSubmitter(ctx, &challengetypes.QueryInturnAttestationSubmitterRequest{})
+	if err != nil {
+		return false, nil, err
+	}
+	inturn := strings.EqualFold(strings.TrimPrefix(resp.BlsPubKey, "0x"), strings.TrimPrefix(blsPubKey, "0x"))
+	return inturn, resp.SubmitInterval, nil
+}
+
+// AttestChallengeInturn skips the attested challenge and the attestation out of turn, which would be rejected on chain
+func (c *client) AttestChallengeInturn(ctx context.Context, blsPubKey string, attestation types.ChallengeAttestation,
+	txOption gnfdsdktypes.TxOption,
+) (*sdk.TxResponse, error) {
+	attested, err := c.IsChallengeAttested(ctx, attestation.ChallengeId)
+	if err != nil {
+		return nil, err
+	}
+	if attested {
+		return nil, fmt.Errorf("%w: %d", types.ErrorChallengeAttested, attestation.ChallengeId)
+	}
+	inturn, interval, err := c.IsInturnAttestationSubmitter(ctx, blsPubKey)
+	if err != nil {
+		return nil, err
+	}
+	if !inturn {
+		if interval != nil {
+			return nil, fmt.Errorf("%w, the current submit interval is from %d to %d", types.ErrorNotInturnSubmitter,
+				interval.Start, interval.End)
+		}
+		return nil, types.ErrorNotInturnSubmitter
+	}
+
+	return c.AttestChallenge(ctx, attestation.SubmitterAddress, attestation.ChallengerAddress, attestation.SPOperatorAddress,
+		attestation.ChallengeId, attestation.ObjectId, attestation.VoteResult, attestation.VoteValidatorSet,
+		attestation.VoteAggSignature, txOption)
+}
+
 // ChallengeParams returns the on chain parameters for challenge module.
 func (c *client) ChallengeParams(ctx context.Context, req *challengetypes.QueryParamsRequest) (*challengetypes.QueryParamsResponse, error) {
 	return c.chainClient.ChallengeQueryClient.Params(ctx, req)
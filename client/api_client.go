@@ -15,10 +15,12 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	hashlib "github.com/bnb-chain/greenfield-common/go/hash"
 	httplib "github.com/bnb-chain/greenfield-common/go/http"
+	"github.com/bnb-chain/greenfield-go-sdk/pkg/ratelimit"
 	"github.com/bnb-chain/greenfield-go-sdk/pkg/utils"
 	"github.com/bnb-chain/greenfield-go-sdk/types"
 	sdkclient "github.com/bnb-chain/greenfield/sdk/client"
@@ -44,6 +46,10 @@ type Client interface {
 	Distribution
 	CrossChain
 	FeeGrant
+	Permission
+	Encryption
+	Migration
+	Audit
 
 	GetDefaultAccount() (*types.Account, error)
 	SetDefaultAccount(account *types.Account)
@@ -71,6 +77,15 @@ type client struct {
 	isTraceEnabled bool
 	traceOutput    io.Writer
 	onlyTraceError bool
+	// the cache of the redundancy params on chain, it is nil if the params are not cached
+	redundancyParams *redundancyParamsCache
+	// the client-wide limiter of the object payload transfer, it is nil if the transfer is not limited
+	rateLimiter *ratelimit.Limiter
+	// the selector of the primary SP of the new bucket and the secondary SPs of the new object, it can be nil
+	spSelector SPSelector
+	// the checker of the SP health installed by SetSPHealthChecker, it is nil if the SP health is not checked
+	spHealthChecker *SPHealthChecker
+	spHealthMtx     sync.RWMutex
 }
 
 // Option is a configuration struct used to provide optional parameters to the client constructor.
@@ -85,6 +100,16 @@ type Option struct {
 	Transport http.RoundTripper
 	// Host is the target sp server hostname
 	Host string
+	// RedundancyParamsRefreshInterval is the interval to refresh the cached redundancy params used to compute the hash roots,
+	// the params are queried from chain every time if it is zero.
+	RedundancyParamsRefreshInterval time.Duration
+	// RateLimit indicates the max bytes per second shared by all the object uploads and downloads of the client,
+	// it is not limited if it is zero.
+	RateLimit int64
+	// SPSelector chooses the SPs when the primary SP of the new bucket or the secondary SPs of the new object
+	// are not specified, and the SP to send the requests which are not routed by bucket.
+	// The first in-service SP on chain is used if it is nil.
+	SPSelector SPSelector
 }
 
 // New - instantiate greenfield chain with chain info, account info and options.
@@ -108,6 +133,13 @@ func New(chainID string, endpoint string, option Option) (Client, error) {
 		defaultAccount: option.DefaultAccount, // it allows to be nil
 		secure:         option.Secure,
 		host:           option.Host,
+		spSelector:     option.SPSelector,
+	}
+	if option.RedundancyParamsRefreshInterval > 0 {
+		c.redundancyParams = &redundancyParamsCache{refreshInterval: option.RedundancyParamsRefreshInterval}
+	}
+	if option.RateLimit > 0 {
+		c.rateLimiter = ratelimit.NewLimiter(option.RateLimit, 0)
 	}
 
 	// fetch sp endpoints info from chain
@@ -141,6 +173,10 @@ func (c *client) getSPUrlByBucket(bucketName string) (*url.URL, error) {
 	}
 
 	primarySP := bucketInfo.GetPrimarySpAddress()
+	// fail fast instead of waiting for the transport error of the unhealthy SP
+	if err = c.checkSPHealth(primarySP); err != nil {
+		return nil, fmt.Errorf("fail to route the request of bucket %s: %w", bucketName, err)
+	}
 	if _, ok := c.spEndpoints[primarySP]; ok {
 		return c.spEndpoints[primarySP], nil
 	}
@@ -177,16 +213,12 @@ func (c *client) getSPUrlByAddr(address string) (*url.URL, error) {
 	return nil, fmt.Errorf("the SP endpoint %s not exists on chain", address)
 }
 
-// getInServiceSP return the first SP endpoint which is in service in SP list
+// getInServiceSP return the endpoint of the in-service SP chosen by the SP selector
 func (c *client) getInServiceSP() (*url.URL, error) {
 	ctx := context.Background()
-	spList, err := c.ListStorageProviders(ctx, true)
+	spList, err := c.selectSPs(ctx, 1)
 	if err != nil {
-		return nil, err
-	}
-
-	if len(spList) == 0 {
-		return nil, errors.New("fail to get SP endpoint")
+		return nil, fmt.Errorf("fail to get SP endpoint: %s", err.Error())
 	}
 
 	var useHttps bool
@@ -621,6 +653,24 @@ func (c *client) sendTxn(ctx context.Context, msg sdk.Msg, opt *gnfdSdkTypes.TxO
 	return resp.TxResponse.TxHash, err
 }
 
+// getEventAttributes returns the attributes of the first typed event in the tx logs whose type ends with the event name,
+// the JSON quotes of the attribute values are trimmed
+func getEventAttributes(txResp *sdk.TxResponse, eventName string) (map[string]string, bool) {
+	for _, logs := range txResp.Logs {
+		for _, event := range logs.Events {
+			if !strings.HasSuffix(event.Type, eventName) {
+				continue
+			}
+			attributes := make(map[string]string, len(event.Attributes))
+			for _, attr := range event.Attributes {
+				attributes[attr.Key] = strings.Trim(attr.Value, "\"")
+			}
+			return attributes, true
+		}
+	}
+	return nil, false
+}
+
 // GetDefaultAccount returns the account address of default account in client
 func (c *client) GetDefaultAccount() (*types.Account, error) {
 	if c.MustGetDefaultAccount() == nil {
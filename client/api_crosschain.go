@@ -2,6 +2,8 @@ package client
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"cosmossdk.io/math"
 	sdkmath "cosmossdk.io/math"
@@ -12,6 +14,8 @@ import (
 	oracletypes "github.com/cosmos/cosmos-sdk/x/oracle/types"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/bnb-chain/greenfield-go-sdk/types"
 )
 
 type CrossChain interface {
@@ -22,10 +26,14 @@ type CrossChain interface {
 	GetChannelReceiveSequence(ctx context.Context, channelId uint32) (uint64, error)
 	GetInturnRelayer(ctx context.Context, req *oracletypes.QueryInturnRelayerRequest) (*oracletypes.QueryInturnRelayerResponse, error)
 	GetCrossChainPackage(ctx context.Context, channelId uint32, sequence uint64) ([]byte, error)
+	GetDecodedCrossChainPackage(ctx context.Context, channelId uint32, sequence uint64) (*types.CrossChainPackage, error)
 
 	MirrorGroup(ctx context.Context, id sdkmath.Uint, txOption gnfdSdkTypes.TxOption) (*sdk.TxResponse, error)
 	MirrorBucket(ctx context.Context, id sdkmath.Uint, txOption gnfdSdkTypes.TxOption) (*sdk.TxResponse, error)
 	MirrorObject(ctx context.Context, id sdkmath.Uint, txOption gnfdSdkTypes.TxOption) (*sdk.TxResponse, error)
+	// WaitForMirror finds the latest mirror package of the bucket, object or group on the channel and waits until
+	// it is acknowledged by BSC or the context is done
+	WaitForMirror(ctx context.Context, channelId uint32, id sdkmath.Uint) (*types.MirrorResult, error)
 }
 
 // TransferOut makes a transfer from Greenfield to BSC
@@ -91,6 +99,7 @@ func (c *client) GetInturnRelayer(ctx context.Context, req *oracletypes.QueryInt
 	return c.chainClient.InturnRelayer(ctx, req)
 }
 
+// GetCrossChainPackage gets the raw cross-chain package sent on the channel, which is the package header followed by the payload
 func (c *client) GetCrossChainPackage(ctx context.Context, channelId uint32, sequence uint64) ([]byte, error) {
 	resp, err := c.chainClient.CrossChainPackage(ctx, &crosschaintypes.QueryCrossChainPackageRequest{ChannelId: channelId, Sequence: sequence})
 	if err != nil {
@@ -121,10 +130,170 @@ func (c *client) MirrorBucket(ctx context.Context, bucketId sdkmath.Uint, txOpti
 
 // MirrorObject mirrors the object to BSC as NFT
 func (c *client) MirrorObject(ctx context.Context, objectId sdkmath.Uint, txOption gnfdSdkTypes.TxOption) (*sdk.TxResponse, error) {
-	msgMirrorBucket := storagetypes.NewMsgMirrorBucket(c.MustGetDefaultAccount().GetAddress(), objectId)
-	txResp, err := c.chainClient.BroadcastTx(ctx, []sdk.Msg{msgMirrorBucket}, &txOption)
+	msgMirrorObject := storagetypes.NewMsgMirrorObject(c.MustGetDefaultAccount().GetAddress(), objectId)
+	txResp, err := c.chainClient.BroadcastTx(ctx, []sdk.Msg{msgMirrorObject}, &txOption)
 	if err != nil {
 		return nil, err
 	}
 	return txResp.TxResponse, nil
 }
+
+// GetDecodedCrossChainPackage gets the cross-chain package sent on the channel and decodes its payload
+func (c *client) GetDecodedCrossChainPackage(ctx context.Context, channelId uint32, sequence uint64) (*types.CrossChainPackage, error) {
+	rawPackage, err := c.GetCrossChainPackage(ctx, channelId, sequence)
+	if err != nil {
+		return nil, err
+	}
+	pack, err := DecodeCrossChainPackage(channelId, rawPackage)
+	if err != nil {
+		return nil, err
+	}
+	pack.Sequence = sequence
+	return pack, nil
+}
+
+// DecodeCrossChainPackage decodes the package header and the payload of the raw cross-chain package,
+// the transfer out channel and the bucket, object and group channels are supported
+func DecodeCrossChainPackage(channelId uint32, rawPackage []byte) (*types.CrossChainPackage, error) {
+	header, err := sdk.DecodePackageHeader(rawPackage)
+	if err != nil {
+		return nil, fmt.Errorf("fail to decode package header: %s", err.Error())
+	}
+	pack := &types.CrossChainPackage{
+		ChannelId:     channelId,
+		PackageType:   header.PackageType,
+		Timestamp:     header.Timestamp,
+		RelayerFee:    header.RelayerFee,
+		AckRelayerFee: header.AckRelayerFee,
+	}
+	payload := rawPackage[sdk.GetPackageHeaderLength(header.PackageType):]
+	// the ack package may be sent without payload
+	if len(payload) == 0 {
+		return pack, nil
+	}
+
+	switch sdk.ChannelID(channelId) {
+	case bridgetypes.TransferOutChannelID:
+		// the ack package carries the refund and the fail ack package carries the original syn package
+		if header.PackageType == sdk.AckCrossChainPackageType {
+			pack.Payload, err = bridgetypes.DeserializeTransferOutRefundPackage(payload)
+		} else {
+			pack.Payload, err = bridgetypes.DeserializeTransferOutSynPackage(payload)
+		}
+	case storagetypes.BucketChannelId, storagetypes.ObjectChannelId, storagetypes.GroupChannelId:
+		var rawPack *storagetypes.CrossChainPackage
+		rawPack, err = storagetypes.DeserializeRawCrossChainPackage(payload)
+		if err != nil {
+			break
+		}
+		pack.OperationType = rawPack.OperationType
+		pack.Payload, err = storagetypes.DeserializeCrossChainPackage(payload, sdk.ChannelID(channelId), header.PackageType)
+	default:
+		return nil, fmt.Errorf("unsupported cross-chain channel %d", channelId)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("fail to decode package payload: %s", err.Error())
+	}
+	return pack, nil
+}
+
+// WaitForMirror scans the sent packages backward from the send sequence of the channel to find the latest mirror
+// package of the resource, then polls the source type of the resource until the ack or fail ack package is handled.
+// The result is returned with the pending status and the context error if the context is done before the outcome.
+func (c *client) WaitForMirror(ctx context.Context, channelId uint32, id sdkmath.Uint) (*types.MirrorResult, error) {
+	result, err := c.findMirrorPackage(ctx, channelId, id)
+	if err != nil {
+		return nil, err
+	}
+
+	ticker := time.NewTicker(types.DefaultMirrorPollInterval)
+	defer ticker.Stop()
+	for {
+		sourceType, err := c.getSourceType(ctx, channelId, id)
+		if err != nil {
+			return result, err
+		}
+		switch sourceType {
+		case storagetypes.SOURCE_TYPE_BSC_CROSS_CHAIN:
+			result.Status = types.MirrorSucceeded
+			return result, nil
+		case storagetypes.SOURCE_TYPE_ORIGIN:
+			// the source type is restored when the fail ack package is handled
+			result.Status = types.MirrorFailed
+			return result, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return result, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// findMirrorPackage returns the latest mirror syn package of the resource sent on the channel
+func (c *client) findMirrorPackage(ctx context.Context, channelId uint32, id sdkmath.Uint) (*types.MirrorResult, error) {
+	sendSequence, err := c.GetChannelSendSequence(ctx, channelId)
+	if err != nil {
+		return nil, err
+	}
+	for i := uint64(0); i < types.MaxMirrorSequenceScan && i < sendSequence; i++ {
+		sequence := sendSequence - 1 - i
+		pack, err := c.GetDecodedCrossChainPackage(ctx, channelId, sequence)
+		if err != nil {
+			return nil, err
+		}
+		if pack.PackageType != sdk.SynCrossChainPackageType || pack.OperationType != storagetypes.OperationMirrorBucket {
+			continue
+		}
+		// the mirror operations share the same operation type on the bucket, object and group channels
+		var packId sdkmath.Uint
+		var owner sdk.AccAddress
+		switch payload := pack.Payload.(type) {
+		case *storagetypes.MirrorBucketSynPackage:
+			packId, owner = sdkmath.NewUintFromBigInt(payload.Id), payload.Owner
+		case *storagetypes.MirrorObjectSynPackage:
+			packId, owner = sdkmath.NewUintFromBigInt(payload.Id), payload.Owner
+		case *storagetypes.MirrorGroupSynPackage:
+			packId, owner = sdkmath.NewUintFromBigInt(payload.Id), payload.Owner
+		default:
+			continue
+		}
+		if packId.Equal(id) {
+			return &types.MirrorResult{
+				ChannelId: channelId,
+				Sequence:  sequence,
+				Id:        id,
+				Owner:     owner.String(),
+				Status:    types.MirrorPending,
+			}, nil
+		}
+	}
+	return nil, types.ErrorMirrorPackageNotFound
+}
+
+// getSourceType returns the source type of the bucket, object or group according to the channel
+func (c *client) getSourceType(ctx context.Context, channelId uint32, id sdkmath.Uint) (storagetypes.SourceType, error) {
+	switch sdk.ChannelID(channelId) {
+	case storagetypes.BucketChannelId:
+		bucketInfo, err := c.HeadBucketByID(ctx, id.String())
+		if err != nil {
+			return 0, err
+		}
+		return bucketInfo.SourceType, nil
+	case storagetypes.ObjectChannelId:
+		objectInfo, err := c.HeadObjectByID(ctx, id.String())
+		if err != nil {
+			return 0, err
+		}
+		return objectInfo.SourceType, nil
+	case storagetypes.GroupChannelId:
+		groupInfo, err := c.HeadGroupByID(ctx, id.String())
+		if err != nil {
+			return 0, err
+		}
+		return groupInfo.SourceType, nil
+	default:
+		return 0, fmt.Errorf("channel %d is not a mirror channel", channelId)
+	}
+}
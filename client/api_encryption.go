@@ -0,0 +1,213 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/bnb-chain/greenfield-go-sdk/pkg/encryption"
+	"github.com/bnb-chain/greenfield-go-sdk/types"
+)
+
+type Encryption interface {
+	// PutEncryptedObject encrypts the payload on client side, creates the object with the hash roots of the ciphertext
+	// and uploads the ciphertext to the primary SP, it returns the txn hash of creating object.
+	// The ciphertext is spooled to a temp file to compute the hash roots and upload, the compression is not supported.
+	PutEncryptedObject(ctx context.Context, bucketName, objectName string, reader io.ReadSeeker,
+		encOpts types.EncryptionOptions, opts types.CreateObjectOptions) (string, error)
+	// GetDecryptedObject downloads and decrypts the object uploaded by PutEncryptedObject,
+	// the range of the option indicates the range of the plaintext
+	GetDecryptedObject(ctx context.Context, bucketName, objectName string, encOpts types.EncryptionOptions,
+		opts types.GetObjectOption) (io.ReadCloser, types.ObjectStat, error)
+}
+
+// PutEncryptedObject encrypts the payload, creates the object and uploads the ciphertext
+func (c *client) PutEncryptedObject(ctx context.Context, bucketName, objectName string, reader io.ReadSeeker,
+	encOpts types.EncryptionOptions, opts types.CreateObjectOptions,
+) (string, error) {
+	if reader == nil {
+		return "", errors.New("fail to encrypt payload, reader is nil")
+	}
+	// the plaintext ranges are mapped to the ciphertext offsets, which the compression would invalidate
+	if opts.Compression != "" {
+		return "", errors.New("compression is not supported for the encrypted object")
+	}
+	// the ciphertext differs on every upload because of the random data key and nonce
+	if opts.SkipIfIdentical {
+		return "", errors.New("skip if identical is not supported for the encrypted object")
+	}
+	plainSize, err := reader.Seek(0, io.SeekEnd)
+	if err != nil {
+		return "", err
+	}
+	if _, err = reader.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	// the ciphertext should not be served as the content type detected from the object name
+	if opts.ContentType == "" {
+		opts.ContentType = types.ContentDefault
+	}
+
+	header, dataKey, err := encryption.NewHeader(encOpts.KeyProvider, objectPath(bucketName, objectName),
+		encOpts.ChunkSize, uint64(plainSize))
+	if err != nil {
+		return "", err
+	}
+	encryptReader, err := encryption.NewEncryptReader(reader, header, dataKey)
+	if err != nil {
+		return "", err
+	}
+
+	// the payload is encrypted only once and the ciphertext is spooled to hash and upload it,
+	// encrypting the payload twice under the same key and nonce is unsafe if the payload changes between the reads
+	cipherFile, err := os.CreateTemp("", "greenfield-encrypted-*")
+	if err != nil {
+		return "", fmt.Errorf("fail to create temp file for ciphertext: %s", err.Error())
+	}
+	defer func() {
+		cipherFile.Close()
+		os.Remove(cipherFile.Name())
+	}()
+	cipherSize, err := io.Copy(cipherFile, encryptReader)
+	if err != nil {
+		return "", fmt.Errorf("fail to encrypt payload: %s", err.Error())
+	}
+	if cipherSize != header.EncryptedSize() {
+		return "", fmt.Errorf("the payload size changed during encryption, expected %d, got %d", header.EncryptedSize(), cipherSize)
+	}
+
+	if _, err = cipherFile.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	txnHash, err := c.CreateObject(ctx, bucketName, objectName, cipherFile, opts)
+	if err != nil {
+		return "", err
+	}
+	txResp, err := c.WaitForTx(ctx, txnHash)
+	if err != nil {
+		return txnHash, fmt.Errorf("fail to wait for creating object %s: %s", objectName, err.Error())
+	}
+	if txResp.Code != 0 {
+		return txnHash, fmt.Errorf("fail to create object %s: %s", objectName, txResp.RawLog)
+	}
+
+	if _, err = cipherFile.Seek(0, io.SeekStart); err != nil {
+		return txnHash, err
+	}
+	err = c.PutObject(ctx, bucketName, objectName, cipherSize, cipherFile,
+		types.PutObjectOptions{ContentType: opts.ContentType, TxnHash: txnHash})
+	return txnHash, err
+}
+
+// GetDecryptedObject downloads the ciphertext which covers the plaintext range and decrypts it
+func (c *client) GetDecryptedObject(ctx context.Context, bucketName, objectName string, encOpts types.EncryptionOptions,
+	opts types.GetObjectOption,
+) (io.ReadCloser, types.ObjectStat, error) {
+	if encOpts.KeyProvider == nil {
+		return nil, types.ObjectStat{}, errors.New("the key provider is nil")
+	}
+	header, dataKey, err := c.getEncryptionHeader(ctx, bucketName, objectName, encOpts.KeyProvider)
+	if err != nil {
+		return nil, types.ObjectStat{}, err
+	}
+
+	plainSize := int64(header.PlainSize)
+	start, end, err := opts.GetRange()
+	if err != nil {
+		return nil, types.ObjectStat{}, err
+	}
+	if start < 0 {
+		start = 0
+	}
+	if end < 0 || end > plainSize-1 {
+		end = plainSize - 1
+	}
+	if start > end {
+		// nothing to read for the empty object or the range beyond the end
+		return io.NopCloser(strings.NewReader("")), types.ObjectStat{ObjectName: objectName, ContentType: types.ContentDefault}, nil
+	}
+
+	cipherStart, cipherEnd, firstChunk, lastChunk := header.CiphertextRange(start, end)
+	cipherOpts := types.GetObjectOption{Progress: opts.Progress, RateLimit: opts.RateLimit}
+	if err = cipherOpts.SetRange(cipherStart, cipherEnd); err != nil {
+		return nil, types.ObjectStat{}, err
+	}
+	body, stat, err := c.GetObject(ctx, bucketName, objectName, cipherOpts)
+	if err != nil {
+		return nil, types.ObjectStat{}, err
+	}
+
+	plainReader, err := encryption.NewDecryptReader(body, header, dataKey, firstChunk, lastChunk)
+	if err != nil {
+		body.Close()
+		return nil, types.ObjectStat{}, err
+	}
+	// skip the plaintext before the start in the first chunk
+	if _, err = io.CopyN(io.Discard, plainReader, start-firstChunk*int64(header.ChunkSize)); err != nil {
+		body.Close()
+		return nil, types.ObjectStat{}, err
+	}
+
+	stat.Size = end - start + 1
+	return &readCloser{Reader: io.LimitReader(plainReader, stat.Size), Closer: body}, stat, nil
+}
+
+// getEncryptionHeader reads the encryption header of the object and recovers the data key
+func (c *client) getEncryptionHeader(ctx context.Context, bucketName, objectName string,
+	provider encryption.KeyProvider,
+) (*encryption.Header, []byte, error) {
+	objectInfo, err := c.HeadObject(ctx, bucketName, objectName)
+	if err != nil {
+		return nil, nil, err
+	}
+	headerSize := int64(encryption.MaxHeaderSize)
+	if int64(objectInfo.PayloadSize) < headerSize {
+		headerSize = int64(objectInfo.PayloadSize)
+	}
+	if headerSize == 0 {
+		return nil, nil, errors.New("the object is not encrypted")
+	}
+
+	headerOpts := types.GetObjectOption{}
+	if err = headerOpts.SetRange(0, headerSize-1); err != nil {
+		return nil, nil, err
+	}
+	body, _, err := c.GetObject(ctx, bucketName, objectName, headerOpts)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer body.Close()
+
+	headerBytes, err := io.ReadAll(body)
+	if err != nil {
+		return nil, nil, err
+	}
+	header, err := encryption.ParseHeader(headerBytes)
+	if err != nil {
+		return nil, nil, err
+	}
+	if header.EncryptedSize() != int64(objectInfo.PayloadSize) {
+		return nil, nil, errors.New("the object size does not match the encryption header")
+	}
+
+	dataKey, err := provider.DecryptDataKey(objectPath(bucketName, objectName), header.KeyID, header.WrappedKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return header, dataKey, nil
+}
+
+// objectPath returns the path of the object which is passed to the key provider
+func objectPath(bucketName, objectName string) string {
+	return bucketName + "/" + objectName
+}
+
+// readCloser reads from the reader and closes the closer
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
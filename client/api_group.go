@@ -2,14 +2,27 @@ package client
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
 
 	sdkmath "cosmossdk.io/math"
+	"github.com/bnb-chain/greenfield-go-sdk/pkg/utils"
 	"github.com/bnb-chain/greenfield-go-sdk/types"
+	gnfdsdk "github.com/bnb-chain/greenfield/sdk/types"
 	gnfdTypes "github.com/bnb-chain/greenfield/types"
 	permTypes "github.com/bnb-chain/greenfield/x/permission/types"
 	storageTypes "github.com/bnb-chain/greenfield/x/storage/types"
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/query"
+	"github.com/cosmos/cosmos-sdk/types/tx"
+	"github.com/rs/zerolog/log"
 )
 
 type Group interface {
@@ -29,10 +42,25 @@ type Group interface {
 	// HeadGroup query the groupInfo on chain, return the group info if exists return err info if group not exist
 	// groupOwnerAddr indicates the HEX-encoded string of the group owner address
 	HeadGroup(ctx context.Context, groupName string, groupOwnerAddr string) (*storageTypes.GroupInfo, error)
+	// SyncGroupMembers makes the members of the group owned by the sender the same as the desired members,
+	// it sends UpdateGroupMember transactions in batches and returns the report of the member changes
+	// desiredMembers indicates the HEX-encoded string list of the desired member addresses
+	SyncGroupMembers(ctx context.Context, groupName string, desiredMembers []string, opts types.SyncGroupMembersOptions) (*types.GroupMembersSyncReport, error)
+	// HeadGroupByID query the groupInfo on chain by the group id, return err info if group not exist
+	HeadGroupByID(ctx context.Context, groupID string) (*storageTypes.GroupInfo, error)
 	// HeadGroupMember query the group member info on chain, return true if the member exists in group
 	// groupOwnerAddr indicates the HEX-encoded string of the group owner address
 	// headMember indicates the HEX-encoded string of the group member address
 	HeadGroupMember(ctx context.Context, groupName string, groupOwner, headMember string) bool
+	// ListGroups list the groups of the owner on chain with pagination
+	// groupOwnerAddr indicates the HEX-encoded string of the group owner address
+	ListGroups(ctx context.Context, groupOwnerAddr string, opts types.ListGroupsOptions) (types.ListGroupsResult, error)
+	// ListGroupMembers list the members of the group from the metadata service of SP with pagination
+	// groupOwnerAddr indicates the HEX-encoded string of the group owner address
+	ListGroupMembers(ctx context.Context, groupName string, groupOwnerAddr string, opts types.ListGroupMembersOptions) (types.ListGroupMembersResult, error)
+	// ListGroupsForMember list the groups which the member belongs to from the metadata service of SP with pagination
+	// memberAddr indicates the HEX-encoded string of the member address
+	ListGroupsForMember(ctx context.Context, memberAddr string, opts types.ListGroupsForMemberOptions) (types.ListGroupsForMemberResult, error)
 	// PutGroupPolicy apply group policy to user specified by principalAddr, the sender need to be the owner of the group
 	// principalAddr indicates the HEX-encoded string of the principal address
 	PutGroupPolicy(ctx context.Context, groupName string, principalAddr string, statements []*permTypes.Statement, opt types.PutPolicyOption) (string, error)
@@ -99,6 +127,113 @@ func (c *client) UpdateGroupMember(ctx context.Context, groupName string, groupO
 	return c.sendTxn(ctx, updateGroupMsg, opts.TxOpts)
 }
 
+// SyncGroupMembers diffs the desired members against the current members of the group and updates the group members in batches
+func (c *client) SyncGroupMembers(ctx context.Context, groupName string, desiredMembers []string,
+	opts types.SyncGroupMembersOptions,
+) (*types.GroupMembersSyncReport, error) {
+	if opts.BatchSize <= 0 || opts.BatchSize > storageTypes.MaxGroupMemberLimitOnce {
+		opts.BatchSize = storageTypes.MaxGroupMemberLimitOnce
+	}
+	groupOwner := c.MustGetDefaultAccount().GetAddress().String()
+
+	desired := make(map[string]bool, len(desiredMembers))
+	for _, addr := range desiredMembers {
+		member, err := sdk.AccAddressFromHexUnsafe(addr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid member address %s: %s", addr, err.Error())
+		}
+		desired[member.String()] = true
+	}
+
+	current, err := c.listAllGroupMembers(ctx, groupName, groupOwner)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &types.GroupMembersSyncReport{
+		GroupName:  groupName,
+		GroupOwner: groupOwner,
+		DryRun:     opts.DryRun,
+	}
+	for _, addr := range desiredMembers {
+		member := sdk.MustAccAddressFromHex(addr).String()
+		if !desired[member] {
+			// skip the duplicated member
+			continue
+		}
+		delete(desired, member)
+		if current[member] {
+			report.Unchanged++
+		} else {
+			report.MembersToAdd = append(report.MembersToAdd, member)
+		}
+		delete(current, member)
+	}
+	for member := range current {
+		report.MembersToRemove = append(report.MembersToRemove, member)
+	}
+	sort.Strings(report.MembersToRemove)
+
+	if opts.DryRun {
+		log.Info().Msg(report.String())
+		return report, nil
+	}
+
+	// set the default txn broadcast mode as block mode
+	if opts.TxOpts == nil {
+		broadcastMode := tx.BroadcastMode_BROADCAST_MODE_BLOCK
+		opts.TxOpts = &gnfdsdk.TxOption{Mode: &broadcastMode}
+	}
+
+	toAdd, toRemove := report.MembersToAdd, report.MembersToRemove
+	for len(toAdd) > 0 || len(toRemove) > 0 {
+		addCount := opts.BatchSize
+		if addCount > len(toAdd) {
+			addCount = len(toAdd)
+		}
+		removeCount := opts.BatchSize - addCount
+		if removeCount > len(toRemove) {
+			removeCount = len(toRemove)
+		}
+
+		txnHash, err := c.UpdateGroupMember(ctx, groupName, groupOwner, toAdd[:addCount], toRemove[:removeCount],
+			types.UpdateGroupMemberOption{TxOpts: opts.TxOpts})
+		if err != nil {
+			return report, fmt.Errorf("fail to update members of group %s: %s", groupName, err.Error())
+		}
+		report.TxnHashes = append(report.TxnHashes, txnHash)
+		toAdd, toRemove = toAdd[addCount:], toRemove[removeCount:]
+	}
+
+	return report, nil
+}
+
+// listAllGroupMembers lists all the members of the group page by page
+func (c *client) listAllGroupMembers(ctx context.Context, groupName string, groupOwner string) (map[string]bool, error) {
+	members := make(map[string]bool)
+	opts := types.ListGroupMembersOptions{Limit: types.DefaultListGroupsLimit}
+	for {
+		result, err := c.ListGroupMembers(ctx, groupName, groupOwner, opts)
+		if err != nil {
+			return nil, fmt.Errorf("fail to list members of group %s: %s", groupName, err.Error())
+		}
+		for _, member := range result.Members {
+			if member.Removed {
+				continue
+			}
+			addr, err := sdk.AccAddressFromHexUnsafe(member.AccountID)
+			if err != nil {
+				return nil, fmt.Errorf("invalid member address %s: %s", member.AccountID, err.Error())
+			}
+			members[addr.String()] = true
+		}
+		if int64(len(result.Members)) < opts.Limit {
+			return members, nil
+		}
+		opts.StartAfter = result.Members[len(result.Members)-1].AccountID
+	}
+}
+
 // LeaveGroup make the member leave the specific group
 func (c *client) LeaveGroup(ctx context.Context, groupName string, groupOwnerAddr string, opt types.LeaveGroupOption) (string, error) {
 	groupOwner, err := sdk.AccAddressFromHexUnsafe(groupOwnerAddr)
@@ -125,6 +260,30 @@ func (c *client) HeadGroup(ctx context.Context, groupName string, groupOwnerAddr
 	return headGroupResponse.GroupInfo, nil
 }
 
+// HeadGroupByID query the groupInfo on chain by the group id, return err info if group not exist
+func (c *client) HeadGroupByID(ctx context.Context, groupID string) (*storageTypes.GroupInfo, error) {
+	headGroupNftResponse, err := c.chainClient.HeadGroupNFT(ctx, &storageTypes.QueryNFTRequest{
+		TokenId: groupID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// the group NFT metadata carries the group info fields as the attributes
+	var groupOwner string
+	for _, attribute := range headGroupNftResponse.MetaData.Attributes {
+		if attribute.TraitType == "Owner" {
+			groupOwner = attribute.Value
+			break
+		}
+	}
+	if groupOwner == "" {
+		return nil, fmt.Errorf("fail to get the owner of group %s", groupID)
+	}
+
+	return c.HeadGroup(ctx, headGroupNftResponse.MetaData.GroupName, groupOwner)
+}
+
 // HeadGroupMember query the group member info on chain, return true if the member exists in group
 func (c *client) HeadGroupMember(ctx context.Context, groupName string, groupOwnerAddr, headMemberAddr string) bool {
 	headGroupRequest := storageTypes.QueryHeadGroupMemberRequest{
@@ -205,3 +364,116 @@ func (c *client) DeleteGroupPolicy(ctx context.Context, groupName string, princi
 
 	return c.sendDelPolicyTxn(ctx, sender, resource, principal, opt.TxOpts)
 }
+
+// ListGroups list the groups of the owner on chain with pagination
+func (c *client) ListGroups(ctx context.Context, groupOwnerAddr string, opts types.ListGroupsOptions) (types.ListGroupsResult, error) {
+	_, err := sdk.AccAddressFromHexUnsafe(groupOwnerAddr)
+	if err != nil {
+		return types.ListGroupsResult{}, err
+	}
+
+	listGroupRequest := storageTypes.QueryListGroupRequest{
+		GroupOwner: groupOwnerAddr,
+		Pagination: &query.PageRequest{
+			Key:   opts.PaginationKey,
+			Limit: opts.Limit,
+		},
+	}
+
+	listGroupResponse, err := c.chainClient.ListGroup(ctx, &listGroupRequest)
+	if err != nil {
+		return types.ListGroupsResult{}, err
+	}
+
+	result := types.ListGroupsResult{Groups: listGroupResponse.GroupInfos}
+	if listGroupResponse.Pagination != nil {
+		result.NextKey = listGroupResponse.Pagination.NextKey
+	}
+	return result, nil
+}
+
+// ListGroupMembers list the members of the group from the metadata service of SP with pagination
+func (c *client) ListGroupMembers(ctx context.Context, groupName string, groupOwnerAddr string,
+	opts types.ListGroupMembersOptions,
+) (types.ListGroupMembersResult, error) {
+	groupInfo, err := c.HeadGroup(ctx, groupName, groupOwnerAddr)
+	if err != nil {
+		return types.ListGroupMembersResult{}, err
+	}
+
+	params := url.Values{}
+	params.Set("group-members", "")
+	params.Set("group-id", groupInfo.Id.String())
+	params.Set("start-after", opts.StartAfter)
+	if opts.Limit > 0 {
+		params.Set("limit", strconv.FormatInt(opts.Limit, 10))
+	}
+
+	listGroupMembersResult := types.ListGroupMembersResult{}
+	if err = c.getGroupMetadata(ctx, params, "", &listGroupMembersResult); err != nil {
+		log.Error().Msg("the list of members of group: " + groupName + " failed: " + err.Error())
+		return types.ListGroupMembersResult{}, err
+	}
+
+	return listGroupMembersResult, nil
+}
+
+// ListGroupsForMember list the groups which the member belongs to from the metadata service of SP with pagination
+func (c *client) ListGroupsForMember(ctx context.Context, memberAddr string,
+	opts types.ListGroupsForMemberOptions,
+) (types.ListGroupsForMemberResult, error) {
+	_, err := sdk.AccAddressFromHexUnsafe(memberAddr)
+	if err != nil {
+		return types.ListGroupsForMemberResult{}, err
+	}
+
+	params := url.Values{}
+	params.Set("user-groups", "")
+	params.Set("start-after", opts.StartAfter)
+	if opts.Limit > 0 {
+		params.Set("limit", strconv.FormatInt(opts.Limit, 10))
+	}
+
+	listGroupsResult := types.ListGroupsForMemberResult{}
+	if err = c.getGroupMetadata(ctx, params, memberAddr, &listGroupsResult); err != nil {
+		log.Error().Msg("the list of groups of member: " + memberAddr + " failed: " + err.Error())
+		return types.ListGroupsForMemberResult{}, err
+	}
+
+	return listGroupsResult, nil
+}
+
+// getGroupMetadata sends the group query to the metadata service of an in-service SP and unmarshal the json result
+func (c *client) getGroupMetadata(ctx context.Context, params url.Values, userAddress string, result interface{}) error {
+	reqMeta := requestMeta{
+		urlValues:     params,
+		contentSHA256: types.EmptyStringSHA256,
+		userAddress:   userAddress,
+	}
+
+	sendOpt := sendOptions{
+		method:           http.MethodGet,
+		disableCloseBody: true,
+	}
+
+	endpoint, err := c.getInServiceSP()
+	if err != nil {
+		log.Error().Msg(fmt.Sprintf("get in-service SP fail %s", err.Error()))
+		return err
+	}
+
+	resp, err := c.sendReq(ctx, reqMeta, &sendOpt, endpoint)
+	if err != nil {
+		return err
+	}
+	defer utils.CloseResponse(resp)
+
+	// unmarshal the json content from response body
+	buf := new(strings.Builder)
+	_, err = io.Copy(buf, resp.Body)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal([]byte(buf.String()), result)
+}
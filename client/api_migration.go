@@ -0,0 +1,404 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	gnfdsdk "github.com/bnb-chain/greenfield/sdk/types"
+	gnfdTypes "github.com/bnb-chain/greenfield/types"
+	"github.com/bnb-chain/greenfield/types/resource"
+	permTypes "github.com/bnb-chain/greenfield/x/permission/types"
+	storageTypes "github.com/bnb-chain/greenfield/x/storage/types"
+	"github.com/cosmos/cosmos-sdk/types/query"
+	"github.com/cosmos/cosmos-sdk/types/tx"
+	"github.com/rs/zerolog/log"
+
+	"github.com/bnb-chain/greenfield-go-sdk/pkg/encryption"
+	"github.com/bnb-chain/greenfield-go-sdk/types"
+)
+
+type Migration interface {
+	// MigrateBucket creates the new bucket on the primary SP specified by dstPrimaryAddr and migrates the objects,
+	// the visibility and the bucket policies of the old bucket to it. The migration is resumable with the state file,
+	// the objects already sealed in the new bucket with the same checksums are not copied again.
+	// The old bucket is deleted by the DeleteSource option only if all its objects on chain are sealed in the new bucket
+	// with the same checksums, and none of them is encrypted on client side.
+	// dstPrimaryAddr indicates the HEX-encoded string of the primary storage provider address of the new bucket
+	MigrateBucket(ctx context.Context, srcBucketName, dstBucketName, dstPrimaryAddr string,
+		opts types.MigrateBucketOptions) (*types.BucketMigrationReport, error)
+}
+
+// MigrateBucket migrates the bucket to the new bucket on another primary SP, it returns the migration report
+// and an error if any object fails to migrate
+func (c *client) MigrateBucket(ctx context.Context, srcBucketName, dstBucketName, dstPrimaryAddr string,
+	opts types.MigrateBucketOptions,
+) (*types.BucketMigrationReport, error) {
+	if srcBucketName == dstBucketName {
+		return nil, errors.New("the new bucket name should be different from the old one")
+	}
+	srcBucket, err := c.HeadBucket(ctx, srcBucketName)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &types.BucketMigrationReport{
+		SrcBucketName:       srcBucketName,
+		DstBucketName:       dstBucketName,
+		SrcPrimarySPAddress: srcBucket.PrimarySpAddress,
+		DstPrimarySPAddress: dstPrimaryAddr,
+		StartTime:           time.Now(),
+		FailedObjects:       make(map[string]string),
+	}
+
+	state, err := c.loadMigrationState(srcBucketName, dstBucketName, dstPrimaryAddr, opts.StateFile)
+	if err != nil {
+		return nil, err
+	}
+	report.Resumed = state.BucketCreated
+	saveState := func() error {
+		if opts.StateFile == "" {
+			return nil
+		}
+		if err := state.Save(opts.StateFile); err != nil {
+			return fmt.Errorf("fail to save the migration state: %s", err.Error())
+		}
+		return nil
+	}
+
+	// the transactions should be committed before the next step
+	if opts.CopyOptions.TxOpts == nil {
+		broadcastMode := tx.BroadcastMode_BROADCAST_MODE_BLOCK
+		opts.CopyOptions.TxOpts = &gnfdsdk.TxOption{Mode: &broadcastMode}
+	}
+	txOpts := opts.CopyOptions.TxOpts
+
+	if err = c.prepareMigrationBucket(ctx, srcBucket, dstBucketName, dstPrimaryAddr, txOpts); err != nil {
+		return report, err
+	}
+	state.BucketCreated = true
+	if err = saveState(); err != nil {
+		return report, err
+	}
+
+	// the objects are listed on chain page by page, the listing of SP is not paginated
+	srcObjects, err := c.listObjectsOnChain(ctx, srcBucketName)
+	if err != nil {
+		return report, err
+	}
+	for _, objectInfo := range srcObjects {
+		objectName := objectInfo.ObjectName
+		if state.MigratedObjects[objectName] {
+			report.SkippedObjects = append(report.SkippedObjects, objectName)
+			continue
+		}
+
+		copied, err := c.migrateObject(ctx, srcBucketName, dstBucketName, objectName, opts.CopyOptions)
+		if err != nil {
+			if ctx.Err() != nil {
+				return report, ctx.Err()
+			}
+			log.Error().Msg(fmt.Sprintf("fail to migrate object %s: %s", objectName, err.Error()))
+			report.FailedObjects[objectName] = err.Error()
+			continue
+		}
+		if copied {
+			report.CopiedObjects = append(report.CopiedObjects, objectName)
+			report.CopiedBytes += objectInfo.PayloadSize
+		} else {
+			report.SkippedObjects = append(report.SkippedObjects, objectName)
+		}
+
+		state.MigratedObjects[objectName] = true
+		if err = saveState(); err != nil {
+			return report, err
+		}
+	}
+
+	if !state.PoliciesReplicated {
+		report.ReplicatedPolicies, err = c.replicateBucketPolicies(ctx, srcBucketName, dstBucketName, opts.PolicyPrincipals, txOpts)
+		if err != nil {
+			return report, err
+		}
+		state.PoliciesReplicated = true
+		if err = saveState(); err != nil {
+			return report, err
+		}
+	}
+
+	if len(report.FailedObjects) > 0 {
+		report.EndTime = time.Now()
+		return report, fmt.Errorf("fail to migrate %d objects of bucket %s", len(report.FailedObjects), srcBucketName)
+	}
+
+	if opts.DeleteSource && !state.SourceDeleted {
+		srcObjects, err = c.checkSourceDeletable(ctx, srcBucketName, dstBucketName, state)
+		if err != nil {
+			report.EndTime = time.Now()
+			return report, fmt.Errorf("refuse to delete the old bucket: %s", err.Error())
+		}
+		for _, objectInfo := range srcObjects {
+			if _, err = c.DeleteObject(ctx, srcBucketName, objectInfo.ObjectName, types.DeleteObjectOption{TxOpts: txOpts}); err != nil {
+				return report, fmt.Errorf("fail to delete object %s of the old bucket: %s", objectInfo.ObjectName, err.Error())
+			}
+		}
+		if _, err = c.DeleteBucket(ctx, srcBucketName, types.DeleteBucketOption{TxOpts: txOpts}); err != nil {
+			return report, fmt.Errorf("fail to delete the old bucket: %s", err.Error())
+		}
+		state.SourceDeleted = true
+		if err = saveState(); err != nil {
+			return report, err
+		}
+	}
+	report.SourceDeleted = state.SourceDeleted
+	report.EndTime = time.Now()
+	return report, nil
+}
+
+// listObjectsOnChain lists all the objects of the bucket on chain page by page
+func (c *client) listObjectsOnChain(ctx context.Context, bucketName string) ([]*storageTypes.ObjectInfo, error) {
+	objects := make([]*storageTypes.ObjectInfo, 0)
+	var nextKey []byte
+	for {
+		resp, err := c.chainClient.StorageQueryClient.ListObjects(ctx, &storageTypes.QueryListObjectsRequest{
+			BucketName: bucketName,
+			Pagination: &query.PageRequest{Key: nextKey},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("fail to list objects of bucket %s: %s", bucketName, err.Error())
+		}
+		objects = append(objects, resp.ObjectInfos...)
+		if resp.Pagination == nil || len(resp.Pagination.NextKey) == 0 {
+			return objects, nil
+		}
+		nextKey = resp.Pagination.NextKey
+	}
+}
+
+// checkSourceDeletable lists the objects of the old bucket on chain again and checks that each of them is migrated
+// and sealed in the new bucket with the same size and checksums, it returns the objects of the old bucket.
+// The objects whose checksums are recomputed with other redundancy params and the encrypted objects are kept
+// in the old bucket, so that the source is never deleted without a verified copy.
+func (c *client) checkSourceDeletable(ctx context.Context, srcBucketName, dstBucketName string,
+	state *types.BucketMigrationState,
+) ([]*storageTypes.ObjectInfo, error) {
+	srcObjects, err := c.listObjectsOnChain(ctx, srcBucketName)
+	if err != nil {
+		return nil, err
+	}
+	dstObjects, err := c.listObjectsOnChain(ctx, dstBucketName)
+	if err != nil {
+		return nil, err
+	}
+	dstInfos := make(map[string]*storageTypes.ObjectInfo, len(dstObjects))
+	for _, objectInfo := range dstObjects {
+		dstInfos[objectInfo.ObjectName] = objectInfo
+	}
+
+	for _, srcInfo := range srcObjects {
+		objectName := srcInfo.ObjectName
+		if !state.MigratedObjects[objectName] {
+			return nil, fmt.Errorf("object %s is not migrated", objectName)
+		}
+		dstInfo, ok := dstInfos[objectName]
+		if !ok || dstInfo.ObjectStatus != storageTypes.OBJECT_STATUS_SEALED {
+			return nil, fmt.Errorf("object %s is not sealed in the new bucket", objectName)
+		}
+		if dstInfo.PayloadSize != srcInfo.PayloadSize || !checksumsEqual(dstInfo.Checksums, srcInfo.Checksums) {
+			return nil, fmt.Errorf("the checksums of object %s in the new bucket differ from the old bucket", objectName)
+		}
+		encrypted, err := c.isEncryptedObject(ctx, srcInfo)
+		if err != nil {
+			return nil, err
+		}
+		if encrypted {
+			return nil, fmt.Errorf("object %s is encrypted on client side", objectName)
+		}
+	}
+	return srcObjects, nil
+}
+
+// isEncryptedObject returns true if the payload of the object begins with the magic of the encryption header
+func (c *client) isEncryptedObject(ctx context.Context, objectInfo *storageTypes.ObjectInfo) (bool, error) {
+	magicSize := int64(len(encryption.HeaderMagic))
+	if int64(objectInfo.PayloadSize) < magicSize {
+		return false, nil
+	}
+	opts := types.GetObjectOption{SkipDecompression: true}
+	if err := opts.SetRange(0, magicSize-1); err != nil {
+		return false, err
+	}
+	body, _, err := c.GetObject(ctx, objectInfo.BucketName, objectInfo.ObjectName, opts)
+	if err != nil {
+		return false, fmt.Errorf("fail to read the header of object %s: %s", objectInfo.ObjectName, err.Error())
+	}
+	defer body.Close()
+	magic := make([]byte, magicSize)
+	if _, err = io.ReadFull(body, magic); err != nil {
+		return false, fmt.Errorf("fail to read the header of object %s: %s", objectInfo.ObjectName, err.Error())
+	}
+	return string(magic) == encryption.HeaderMagic, nil
+}
+
+// loadMigrationState loads the migration state from the state file, or returns the initial state
+func (c *client) loadMigrationState(srcBucketName, dstBucketName, dstPrimaryAddr, stateFile string) (*types.BucketMigrationState, error) {
+	if stateFile != "" {
+		state, err := types.LoadBucketMigrationState(stateFile)
+		if err != nil {
+			return nil, err
+		}
+		if state != nil {
+			if state.SrcBucketName != srcBucketName || state.DstBucketName != dstBucketName ||
+				!strings.EqualFold(state.DstPrimarySPAddress, dstPrimaryAddr) {
+				return nil, fmt.Errorf("the state file %s belongs to the migration of bucket %s to %s",
+					stateFile, state.SrcBucketName, state.DstBucketName)
+			}
+			return state, nil
+		}
+	}
+	return &types.BucketMigrationState{
+		SrcBucketName:       srcBucketName,
+		DstBucketName:       dstBucketName,
+		DstPrimarySPAddress: dstPrimaryAddr,
+		MigratedObjects:     make(map[string]bool),
+	}, nil
+}
+
+// prepareMigrationBucket creates the new bucket with the visibility, the payment account and the read quota of the old
+// bucket, or checks the new bucket created by the interrupted migration
+func (c *client) prepareMigrationBucket(ctx context.Context, srcBucket *storageTypes.BucketInfo, dstBucketName, dstPrimaryAddr string,
+	txOpts *gnfdsdk.TxOption,
+) error {
+	dstBucket, err := c.HeadBucket(ctx, dstBucketName)
+	if err != nil {
+		if !strings.Contains(err.Error(), storageTypes.ErrNoSuchBucket.Error()) {
+			return err
+		}
+		_, err = c.CreateBucket(ctx, dstBucketName, dstPrimaryAddr, types.CreateBucketOptions{
+			Visibility:     srcBucket.Visibility,
+			TxOpts:         txOpts,
+			PaymentAddress: srcBucket.PaymentAddress,
+			ChargedQuota:   srcBucket.ChargedReadQuota,
+		})
+		if err != nil {
+			return fmt.Errorf("fail to create bucket %s: %s", dstBucketName, err.Error())
+		}
+		return nil
+	}
+
+	if !strings.EqualFold(dstBucket.PrimarySpAddress, dstPrimaryAddr) {
+		return fmt.Errorf("bucket %s already exists on primary SP %s", dstBucketName, dstBucket.PrimarySpAddress)
+	}
+	if dstBucket.Visibility != srcBucket.Visibility {
+		if _, err = c.UpdateBucketVisibility(ctx, dstBucketName, srcBucket.Visibility, types.UpdateVisibilityOption{TxOpts: txOpts}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateObject copies the object to the new bucket and waits for it to be sealed, it returns false if the object
+// was already migrated
+func (c *client) migrateObject(ctx context.Context, srcBucketName, dstBucketName, objectName string,
+	opts types.CopyObjectOptions,
+) (bool, error) {
+	srcInfo, err := c.HeadObject(ctx, srcBucketName, objectName)
+	if err != nil {
+		return false, err
+	}
+	if srcInfo.ObjectStatus != storageTypes.OBJECT_STATUS_SEALED {
+		return false, errors.New("the object is not sealed")
+	}
+
+	dstInfo, err := c.HeadObject(ctx, dstBucketName, objectName)
+	if err == nil {
+		switch {
+		case dstInfo.ObjectStatus == storageTypes.OBJECT_STATUS_SEALED:
+			if dstInfo.PayloadSize == srcInfo.PayloadSize && checksumsEqual(dstInfo.Checksums, srcInfo.Checksums) {
+				return false, nil
+			}
+			return false, errors.New("the object already exists in the new bucket with different content")
+		case dstInfo.ObjectStatus == storageTypes.OBJECT_STATUS_CREATED:
+			// the object is left by the interrupted migration, create it again
+			if _, err = c.CancelCreateObject(ctx, dstBucketName, objectName, types.CancelCreateOption{TxOpts: opts.TxOpts}); err != nil {
+				return false, fmt.Errorf("fail to cancel the unsealed object in the new bucket: %s", err.Error())
+			}
+		default:
+			return false, fmt.Errorf("the object in the new bucket is %s", dstInfo.ObjectStatus.String())
+		}
+	}
+
+	// keep the visibility of the source object
+	opts.Visibility = storageTypes.VISIBILITY_TYPE_UNSPECIFIED
+	if _, err = c.CopyObject(ctx, srcBucketName, objectName, dstBucketName, objectName, opts); err != nil {
+		return false, err
+	}
+	if err = c.waitForObjectSealed(ctx, dstBucketName, objectName, opts.SealTimeout); err != nil {
+		return false, err
+	}
+
+	// the payload is verified against the checksums by the SPs before sealing
+	dstInfo, err = c.HeadObject(ctx, dstBucketName, objectName)
+	if err != nil {
+		return false, err
+	}
+	if dstInfo.PayloadSize != srcInfo.PayloadSize || dstInfo.ContentType != srcInfo.ContentType {
+		return false, errors.New("the migrated object does not match the source object")
+	}
+	return true, nil
+}
+
+// replicateBucketPolicies copies the bucket policies of the principals to the new bucket, the object resources in
+// the statements are rewritten to the new bucket. It returns the principals whose policies are replicated.
+func (c *client) replicateBucketPolicies(ctx context.Context, srcBucketName, dstBucketName string,
+	principalList types.PolicyPrincipal, txOpts *gnfdsdk.TxOption,
+) ([]string, error) {
+	principals, err := principalsFromPolicyPrincipal(principalList)
+	if err != nil {
+		return nil, err
+	}
+
+	srcTarget := &policyTarget{resourceType: resource.RESOURCE_TYPE_BUCKET, bucketName: srcBucketName}
+	srcObjectPrefix := gnfdTypes.NewObjectGRN(srcBucketName, "").String()
+	dstObjectPrefix := gnfdTypes.NewObjectGRN(dstBucketName, "").String()
+	dstResource := gnfdTypes.NewBucketGRN(dstBucketName).String()
+
+	replicated := make([]string, 0, len(principals))
+	for _, principal := range principals {
+		policy, err := c.getPolicyOfTarget(ctx, srcTarget, principal)
+		if err != nil {
+			return replicated, fmt.Errorf("fail to get policy of %s: %s", principal.String(), err.Error())
+		}
+		if policy == nil {
+			continue
+		}
+		if policy.ExpirationTime != nil && policy.ExpirationTime.Before(time.Now()) {
+			log.Info().Msg(fmt.Sprintf("skip the expired bucket policy of %s", principal.String()))
+			continue
+		}
+
+		statements := make([]*permTypes.Statement, 0, len(policy.Statements))
+		for _, s := range policy.Statements {
+			statement := *s
+			statement.Resources = make([]string, 0, len(s.Resources))
+			for _, res := range s.Resources {
+				if strings.HasPrefix(res, srcObjectPrefix) {
+					res = dstObjectPrefix + strings.TrimPrefix(res, srcObjectPrefix)
+				}
+				statement.Resources = append(statement.Resources, res)
+			}
+			statements = append(statements, &statement)
+		}
+
+		msg := storageTypes.NewMsgPutPolicy(c.MustGetDefaultAccount().GetAddress(), dstResource, principal, statements,
+			policy.ExpirationTime)
+		if _, err = c.sendTxn(ctx, msg, txOpts); err != nil {
+			return replicated, fmt.Errorf("fail to replicate policy of %s: %s", principal.String(), err.Error())
+		}
+		replicated = append(replicated, principal.String())
+	}
+	return replicated, nil
+}
@@ -15,8 +15,9 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
-	hashlib "github.com/bnb-chain/greenfield-common/go/hash"
 	gnfdsdk "github.com/bnb-chain/greenfield/sdk/types"
 	gnfdTypes "github.com/bnb-chain/greenfield/types"
 	"github.com/bnb-chain/greenfield/types/s3util"
@@ -26,6 +27,8 @@ import (
 	"github.com/cosmos/cosmos-sdk/types/tx"
 	"github.com/rs/zerolog/log"
 
+	"github.com/bnb-chain/greenfield-go-sdk/pkg/compression"
+	"github.com/bnb-chain/greenfield-go-sdk/pkg/ratelimit"
 	"github.com/bnb-chain/greenfield-go-sdk/pkg/utils"
 	"github.com/bnb-chain/greenfield-go-sdk/types"
 )
@@ -61,8 +64,12 @@ type Object interface {
 	IsObjectPermissionAllowed(ctx context.Context, userAddr string, bucketName, objectName string, action permTypes.ActionType) (permTypes.Effect, error)
 
 	ListObjects(ctx context.Context, bucketName string, opts types.ListObjectsOptions) (types.ListObjectsResult, error)
+	// FindDuplicateObjects finds the sealed objects with the identical checksums in the bucket
+	FindDuplicateObjects(ctx context.Context, bucketName string) ([]types.DuplicateObjects, error)
 	// ComputeHashRoots compute the integrity hash, content size and the redundancy type of the file
 	ComputeHashRoots(reader io.Reader) ([][]byte, int64, storageTypes.RedundancyType, error)
+	// GetRedundancyParams return the data shards, parity shards and segment size of the redundancy params on chain
+	GetRedundancyParams() (uint32, uint32, uint64, error)
 
 	// CreateFolder creates an empty object used as folder.
 	// objectName must ending with a forward slash (/) character
@@ -70,32 +77,97 @@ type Object interface {
 
 	// GetObjectUploadProgress return the status of the uploading object
 	GetObjectUploadProgress(ctx context.Context, bucketName, objectName string) (string, error)
+
+	// CopyObject copies the sealed object to the destination bucket, the buckets may be stored on different primary SPs
+	CopyObject(ctx context.Context, srcBucketName, srcObjectName, dstBucketName, dstObjectName string, opts types.CopyObjectOptions) (string, error)
+	// MoveObject copies the object and deletes the source object after the destination object is sealed
+	MoveObject(ctx context.Context, srcBucketName, srcObjectName, dstBucketName, dstObjectName string, opts types.CopyObjectOptions) (string, error)
 }
 
 // GetRedundancyParams query and return the data shards, parity shards and segment size of redundancy
-// configuration on chain
+// configuration on chain, the params are cached if the refresh interval of the redundancy params is set
 func (c *client) GetRedundancyParams() (uint32, uint32, uint64, error) {
+	if c.redundancyParams != nil {
+		params, err := c.redundancyParams.get(c.queryRedundancyParams)
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		return params.DataShards, params.ParityShards, params.SegmentSize, nil
+	}
+
+	params, err := c.queryRedundancyParams()
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	return params.DataShards, params.ParityShards, params.SegmentSize, nil
+}
+
+// queryRedundancyParams query the redundancy params from chain
+func (c *client) queryRedundancyParams() (types.ComputeHashOptions, error) {
 	query := storageTypes.QueryParamsRequest{}
 	queryResp, err := c.chainClient.StorageQueryClient.Params(context.Background(), &query)
 	if err != nil {
-		return 0, 0, 0, err
+		return types.ComputeHashOptions{}, err
 	}
 
 	params := queryResp.Params
-	return params.GetRedundantDataChunkNum(), params.GetRedundantParityChunkNum(), params.GetMaxSegmentSize(), nil
+	return types.ComputeHashOptions{
+		SegmentSize:  params.GetMaxSegmentSize(),
+		DataShards:   params.GetRedundantDataChunkNum(),
+		ParityShards: params.GetRedundantParityChunkNum(),
+	}, nil
+}
+
+// redundancyParamsCache caches the redundancy params on chain and refreshes them after the refresh interval
+type redundancyParamsCache struct {
+	mu              sync.Mutex
+	params          types.ComputeHashOptions
+	expireTime      time.Time
+	refreshInterval time.Duration
+}
+
+// get returns the cached params, the params are queried by the query function if the cache is expired
+func (p *redundancyParamsCache) get(query func() (types.ComputeHashOptions, error)) (types.ComputeHashOptions, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if time.Now().Before(p.expireTime) {
+		return p.params, nil
+	}
+	params, err := query()
+	if err != nil {
+		return types.ComputeHashOptions{}, err
+	}
+	p.params = params
+	p.expireTime = time.Now().Add(p.refreshInterval)
+	return params, nil
 }
 
 // ComputeHashRoots return the integrity hash, content size and the redundancy type of the file
 func (c *client) ComputeHashRoots(reader io.Reader) ([][]byte, int64, storageTypes.RedundancyType, error) {
-	dataBlocks, parityBlocks, segSize, err := c.GetRedundancyParams()
+	return c.computeHashRoots(reader, false, nil)
+}
+
+// computeHashRoots return the integrity hash, content size and the redundancy type of the payload,
+// the redundancy params on chain are used if the hash options are not specified
+func (c *client) computeHashRoots(reader io.Reader, isReplicaType bool, opts *types.ComputeHashOptions) ([][]byte, int64, storageTypes.RedundancyType, error) {
+	redundancyType := storageTypes.REDUNDANCY_EC_TYPE
+	if isReplicaType {
+		redundancyType = storageTypes.REDUNDANCY_REPLICA_TYPE
+	}
 	if reader == nil {
-		return nil, 0, storageTypes.REDUNDANCY_EC_TYPE, errors.New("fail to compute hash, reader is nil")
+		return nil, 0, redundancyType, errors.New("fail to compute hash, reader is nil")
 	}
-	if err != nil {
-		return nil, 0, storageTypes.REDUNDANCY_EC_TYPE, err
+
+	if opts == nil {
+		dataBlocks, parityBlocks, segSize, err := c.GetRedundancyParams()
+		if err != nil {
+			return nil, 0, redundancyType, err
+		}
+		opts = &types.ComputeHashOptions{SegmentSize: segSize, DataShards: dataBlocks, ParityShards: parityBlocks}
 	}
 
-	return hashlib.ComputeIntegrityHash(reader, int64(segSize), int(dataBlocks), int(parityBlocks))
+	return utils.ComputeHashRootsWithParams(reader, redundancyType, *opts)
 }
 
 // CreateObject get approval of creating object and send createObject txn to greenfield chain
@@ -114,29 +186,69 @@ func (c *client) CreateObject(ctx context.Context, bucketName, objectName string
 		return "", err
 	}
 
+	// detect the content type of the payload before compression
+	contentType := opts.ContentType
+	if contentType == "" {
+		var err error
+		contentType, reader, err = utils.DetectContentType(objectName, reader)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	var compressReader *compression.CompressReader
+	if opts.Compression != "" {
+		codec, err := compression.GetCodec(opts.Compression)
+		if err != nil {
+			return "", err
+		}
+		compressReader = compression.NewCompressReader(reader, codec)
+		defer compressReader.Close()
+		reader = compressReader
+	}
+
 	// compute hash root of payload
-	expectCheckSums, size, redundancyType, err := c.ComputeHashRoots(reader)
+	expectCheckSums, size, redundancyType, err := c.computeHashRoots(reader, opts.IsReplicaType, opts.HashOptions)
 	if err != nil {
 		return "", err
 	}
 
-	var contentType string
-	if opts.ContentType != "" {
-		contentType = opts.ContentType
-	} else {
-		contentType = types.ContentDefault
+	if opts.SkipIfIdentical {
+		objectInfo, err := c.HeadObject(ctx, bucketName, objectName)
+		if err == nil && objectInfo.PayloadSize == uint64(size) && checksumsEqual(objectInfo.Checksums, expectCheckSums) {
+			log.Info().Msg(fmt.Sprintf("skip creating object %s, the object with identical content exists", objectName))
+			return "", types.ErrorObjectIdentical
+		}
+	}
+
+	if compressReader != nil {
+		contentType = compression.EncodeContentType(contentType, opts.Compression, compressReader.LogicalSize())
 	}
 
-	var visibility storageTypes.VisibilityType
-	if opts.Visibility == storageTypes.VISIBILITY_TYPE_UNSPECIFIED {
+	return c.createObjectWithChecksums(ctx, bucketName, objectName, uint64(size), expectCheckSums, contentType,
+		redundancyType, opts.Visibility, opts.SecondarySPAccs, opts.TxOpts)
+}
+
+// createObjectWithChecksums gets the approval of the primary SP and sends the createObject txn with the checksums
+func (c *client) createObjectWithChecksums(ctx context.Context, bucketName, objectName string, size uint64, checksums [][]byte,
+	contentType string, redundancyType storageTypes.RedundancyType, visibility storageTypes.VisibilityType,
+	secondarySPAccs []sdk.AccAddress, txOpts *gnfdsdk.TxOption,
+) (string, error) {
+	if visibility == storageTypes.VISIBILITY_TYPE_UNSPECIFIED {
 		visibility = storageTypes.VISIBILITY_TYPE_INHERIT // set default visibility type
-	} else {
-		visibility = opts.Visibility
+	}
+
+	if len(secondarySPAccs) == 0 && c.spSelector != nil {
+		var err error
+		secondarySPAccs, err = c.selectSecondarySPs(ctx, bucketName)
+		if err != nil {
+			return "", fmt.Errorf("fail to select the secondary SPs: %s", err.Error())
+		}
 	}
 
 	createObjectMsg := storageTypes.NewMsgCreateObject(c.MustGetDefaultAccount().GetAddress(), bucketName, objectName,
-		uint64(size), visibility, expectCheckSums, contentType, redundancyType, math.MaxUint, nil, opts.SecondarySPAccs)
-	err = createObjectMsg.ValidateBasic()
+		size, visibility, checksums, contentType, redundancyType, math.MaxUint, nil, secondarySPAccs)
+	err := createObjectMsg.ValidateBasic()
 	if err != nil {
 		return "", err
 	}
@@ -147,18 +259,185 @@ func (c *client) CreateObject(ctx context.Context, bucketName, objectName string
 	}
 
 	// set the default txn broadcast mode as block mode
-	if opts.TxOpts == nil {
+	if txOpts == nil {
 		broadcastMode := tx.BroadcastMode_BROADCAST_MODE_BLOCK
-		opts.TxOpts = &gnfdsdk.TxOption{Mode: &broadcastMode}
+		txOpts = &gnfdsdk.TxOption{Mode: &broadcastMode}
 	}
 
-	resp, err := c.chainClient.BroadcastTx(ctx, []sdk.Msg{signedCreateObjectMsg}, opts.TxOpts)
+	resp, err := c.chainClient.BroadcastTx(ctx, []sdk.Msg{signedCreateObjectMsg}, txOpts)
 	if err != nil {
 		return "", err
 	}
 	return resp.TxResponse.TxHash, err
 }
 
+// selectSecondarySPs chooses the expected secondary SPs of the new object by the SP selector,
+// the primary SP of the bucket is excluded and one SP is chosen for each redundancy piece
+func (c *client) selectSecondarySPs(ctx context.Context, bucketName string) ([]sdk.AccAddress, error) {
+	bucketInfo, err := c.HeadBucket(ctx, bucketName)
+	if err != nil {
+		return nil, err
+	}
+	dataShards, parityShards, _, err := c.GetRedundancyParams()
+	if err != nil {
+		return nil, err
+	}
+
+	spList, err := c.selectSPs(ctx, int(dataShards+parityShards), bucketInfo.GetPrimarySpAddress())
+	if err != nil {
+		return nil, err
+	}
+	secondarySPAccs := make([]sdk.AccAddress, 0, len(spList))
+	for _, sp := range spList {
+		secondarySPAccs = append(secondarySPAccs, sp.GetOperator())
+	}
+	return secondarySPAccs, nil
+}
+
+// CopyObject copies the sealed object to the destination bucket which may be stored on another primary SP.
+// The payload is streamed from the source SP to the destination SP without decompression or decryption,
+// the content type, the redundancy type and the visibility of the source object are kept.
+// It returns the txn hash of creating the destination object after the payload is uploaded.
+func (c *client) CopyObject(ctx context.Context, srcBucketName, srcObjectName, dstBucketName, dstObjectName string,
+	opts types.CopyObjectOptions,
+) (string, error) {
+	if err := s3util.CheckValidBucketName(dstBucketName); err != nil {
+		return "", err
+	}
+	if err := s3util.CheckValidObjectName(dstObjectName); err != nil {
+		return "", err
+	}
+	if srcBucketName == dstBucketName && srcObjectName == dstObjectName {
+		return "", errors.New("the source object and the destination object are the same")
+	}
+
+	srcInfo, err := c.HeadObject(ctx, srcBucketName, srcObjectName)
+	if err != nil {
+		return "", err
+	}
+	if srcInfo.ObjectStatus != storageTypes.OBJECT_STATUS_SEALED {
+		return "", fmt.Errorf("fail to copy object %s, the object is not sealed", srcObjectName)
+	}
+
+	checksums, err := c.copyChecksums(ctx, srcInfo, opts.SourceHashOptions)
+	if err != nil {
+		return "", err
+	}
+
+	visibility := opts.Visibility
+	if visibility == storageTypes.VISIBILITY_TYPE_UNSPECIFIED {
+		visibility = srcInfo.Visibility
+	}
+	txnHash, err := c.createObjectWithChecksums(ctx, dstBucketName, dstObjectName, srcInfo.PayloadSize, checksums,
+		srcInfo.ContentType, srcInfo.RedundancyType, visibility, opts.SecondarySPAccs, opts.TxOpts)
+	if err != nil {
+		return "", err
+	}
+	txResp, err := c.WaitForTx(ctx, txnHash)
+	if err != nil {
+		return txnHash, fmt.Errorf("fail to wait for creating object %s: %s", dstObjectName, err.Error())
+	}
+	if txResp.Code != 0 {
+		return txnHash, fmt.Errorf("fail to create object %s: %s", dstObjectName, txResp.RawLog)
+	}
+	// the empty object is sealed on creation
+	if srcInfo.PayloadSize == 0 {
+		return txnHash, nil
+	}
+
+	body, _, err := c.GetObject(ctx, srcBucketName, srcObjectName, types.GetObjectOption{SkipDecompression: true})
+	if err != nil {
+		return txnHash, err
+	}
+	defer body.Close()
+
+	err = c.PutObject(ctx, dstBucketName, dstObjectName, int64(srcInfo.PayloadSize), body, types.PutObjectOptions{
+		ContentType: srcInfo.ContentType,
+		TxnHash:     txnHash,
+		Progress:    opts.Progress,
+		RateLimit:   opts.RateLimit,
+	})
+	return txnHash, err
+}
+
+// MoveObject copies the object to the destination bucket and deletes the source object after the destination object
+// is sealed, it returns the txn hash of deleting the source object
+func (c *client) MoveObject(ctx context.Context, srcBucketName, srcObjectName, dstBucketName, dstObjectName string,
+	opts types.CopyObjectOptions,
+) (string, error) {
+	if _, err := c.CopyObject(ctx, srcBucketName, srcObjectName, dstBucketName, dstObjectName, opts); err != nil {
+		return "", err
+	}
+
+	if err := c.waitForObjectSealed(ctx, dstBucketName, dstObjectName, opts.SealTimeout); err != nil {
+		return "", err
+	}
+
+	txnHash, err := c.DeleteObject(ctx, srcBucketName, srcObjectName, types.DeleteObjectOption{TxOpts: opts.TxOpts})
+	if err != nil {
+		return "", fmt.Errorf("the object is copied to %s/%s but fail to delete the source object: %s",
+			dstBucketName, dstObjectName, err.Error())
+	}
+	return txnHash, nil
+}
+
+// copyChecksums returns the checksums of the source object if it is known to be created with the redundancy params on
+// chain by srcParams, otherwise it reads the source object and computes the checksums with the params on chain.
+// The segment size is not recorded on chain, so the checksums are always recomputed without srcParams.
+// The recomputation streams the whole source object from the SP once more, the memory is bounded by the segment size
+// and the hash parallelism instead of the object size.
+func (c *client) copyChecksums(ctx context.Context, srcInfo *storageTypes.ObjectInfo, srcParams *types.ComputeHashOptions) ([][]byte, error) {
+	dataBlocks, parityBlocks, segSize, err := c.GetRedundancyParams()
+	if err != nil {
+		return nil, err
+	}
+	if srcParams != nil && len(srcInfo.Checksums) == int(1+dataBlocks+parityBlocks) && srcParams.SegmentSize == segSize &&
+		srcParams.DataShards == dataBlocks && srcParams.ParityShards == parityBlocks {
+		return srcInfo.Checksums, nil
+	}
+
+	log.Info().Msg(fmt.Sprintf("the redundancy params of object %s are unknown or differ from the params on chain, recompute the checksums",
+		srcInfo.ObjectName))
+	body, _, err := c.GetObject(ctx, srcInfo.BucketName, srcInfo.ObjectName, types.GetObjectOption{SkipDecompression: true})
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	checksums, size, _, err := c.computeHashRoots(body, srcInfo.RedundancyType == storageTypes.REDUNDANCY_REPLICA_TYPE,
+		&types.ComputeHashOptions{SegmentSize: segSize, DataShards: dataBlocks, ParityShards: parityBlocks})
+	if err != nil {
+		return nil, err
+	}
+	if uint64(size) != srcInfo.PayloadSize {
+		return nil, fmt.Errorf("the size of object %s read from SP is %d, expected %d", srcInfo.ObjectName, size, srcInfo.PayloadSize)
+	}
+	return checksums, nil
+}
+
+// waitForObjectSealed polls the object status until the object is sealed or the timeout expires
+func (c *client) waitForObjectSealed(ctx context.Context, bucketName, objectName string, timeout time.Duration) error {
+	if timeout <= 0 {
+		timeout = types.DefaultSealTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(types.SealPollInterval)
+	defer ticker.Stop()
+	for {
+		objectInfo, err := c.HeadObject(ctx, bucketName, objectName)
+		if err == nil && objectInfo.ObjectStatus == storageTypes.OBJECT_STATUS_SEALED {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("fail to wait for object %s to be sealed: %s", objectName, ctx.Err().Error())
+		case <-ticker.C:
+		}
+	}
+}
+
 // DeleteObject send DeleteBucket txn to greenfield chain and return txn hash
 func (c *client) DeleteObject(ctx context.Context, bucketName, objectName string, opt types.DeleteObjectOption) (string, error) {
 	if err := s3util.CheckValidBucketName(bucketName); err != nil {
@@ -192,16 +471,35 @@ func (c *client) CancelCreateObject(ctx context.Context, bucketName, objectName
 func (c *client) PutObject(ctx context.Context, bucketName, objectName string, objectSize int64,
 	reader io.Reader, opts types.PutObjectOptions,
 ) (err error) {
-	if objectSize <= 0 {
-		return errors.New("object size should be more than 0")
+	contentType := opts.ContentType
+	if opts.Compression != "" {
+		codec, err := compression.GetCodec(opts.Compression)
+		if err != nil {
+			return err
+		}
+		// the compressed size and the content type with the codec are recorded on chain when creating object
+		objectInfo, err := c.HeadObject(ctx, bucketName, objectName)
+		if err != nil {
+			return err
+		}
+		objectSize = int64(objectInfo.PayloadSize)
+		contentType = objectInfo.ContentType
+
+		compressReader := compression.NewCompressReader(reader, codec)
+		defer compressReader.Close()
+		reader = compressReader
+	}
+	if contentType == "" {
+		contentType, reader, err = utils.DetectContentType(objectName, reader)
+		if err != nil {
+			return err
+		}
 	}
 
-	var contentType string
-	if opts.ContentType != "" {
-		contentType = opts.ContentType
-	} else {
-		contentType = types.ContentDefault
+	if objectSize <= 0 {
+		return errors.New("object size should be more than 0")
 	}
+	reader = c.wrapTransferReader(ctx, reader, objectSize, opts.Progress, opts.RateLimit)
 
 	reqMeta := requestMeta{
 		bucketName:    bucketName,
@@ -300,8 +598,74 @@ func (c *client) GetObject(ctx context.Context, bucketName, objectName string,
 		utils.CloseResponse(resp)
 		return nil, types.ObjectStat{}, err
 	}
+	resp.Body = &readCloser{
+		Reader: c.wrapTransferReader(ctx, resp.Body, resp.ContentLength, opts.Progress, opts.RateLimit),
+		Closer: resp.Body,
+	}
+
+	if objStat.Codec == "" || opts.SkipDecompression || opts.Range != "" {
+		return resp.Body, objStat, nil
+	}
+
+	// decompress the compressed object transparently
+	codec, err := compression.GetCodec(objStat.Codec)
+	if err != nil {
+		utils.CloseResponse(resp)
+		return nil, types.ObjectStat{}, err
+	}
+	decompressReader, err := codec.NewReader(resp.Body)
+	if err != nil {
+		utils.CloseResponse(resp)
+		return nil, types.ObjectStat{}, err
+	}
+	objStat.Size = objStat.LogicalSize
+	return &decompressReadCloser{ReadCloser: decompressReader, body: resp.Body}, objStat, nil
+}
+
+// decompressReadCloser closes both the decompressor and the response body
+type decompressReadCloser struct {
+	io.ReadCloser
+	body io.ReadCloser
+}
+
+func (r *decompressReadCloser) Close() error {
+	r.ReadCloser.Close()
+	return r.body.Close()
+}
+
+// wrapTransferReader wraps the payload reader with the progress reporter, the rate limiter of the call and the
+// client-wide rate limiter, the total is the size of the payload transferred, -1 if it is unknown
+func (c *client) wrapTransferReader(ctx context.Context, reader io.Reader, total int64,
+	progress types.ProgressFunc, rateLimit int64,
+) io.Reader {
+	var limiter *ratelimit.Limiter
+	if rateLimit > 0 {
+		limiter = ratelimit.NewLimiter(rateLimit, 0)
+	}
+	if limiter != nil || c.rateLimiter != nil {
+		reader = ratelimit.NewReader(ctx, reader, limiter, c.rateLimiter)
+	}
+	if progress != nil {
+		reader = &progressReader{reader: reader, total: total, progress: progress}
+	}
+	return reader
+}
 
-	return resp.Body, objStat, nil
+// progressReader reports the bytes read after each read
+type progressReader struct {
+	reader      io.Reader
+	total       int64
+	transferred int64
+	progress    types.ProgressFunc
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if n > 0 {
+		r.transferred += int64(n)
+		r.progress(r.transferred, r.total)
+	}
+	return n, err
 }
 
 // FGetObject download s3 object payload adn write the object content into local file specified by filePath
@@ -358,11 +722,21 @@ func getObjInfo(objectName string, h http.Header) (types.ObjectStat, error) {
 		contentType = types.ContentDefault
 	}
 
-	return types.ObjectStat{
+	objStat := types.ObjectStat{
 		ObjectName:  objectName,
 		ContentType: contentType,
 		Size:        size,
-	}, nil
+		StoredSize:  size,
+		LogicalSize: size,
+	}
+	// the codec and the logical size of the compressed object are recorded in the content type
+	if mediaType, codec, logicalSize := compression.DecodeContentType(contentType); codec != "" {
+		objStat.ContentType = mediaType
+		objStat.Codec = codec
+		objStat.LogicalSize = logicalSize
+	}
+
+	return objStat, nil
 }
 
 // HeadObject query the objectInfo on chain to check th object id, return the object info if exists
@@ -530,6 +904,59 @@ func (c *client) ListObjects(ctx context.Context, bucketName string, opts types.
 	return types.ListObjectsResult{Objects: objectMetaList}, nil
 }
 
+// FindDuplicateObjects lists the objects of the bucket and groups the sealed objects by checksums,
+// only the groups with more than one object are returned
+func (c *client) FindDuplicateObjects(ctx context.Context, bucketName string) ([]types.DuplicateObjects, error) {
+	listResult, err := c.ListObjects(ctx, bucketName, types.ListObjectsOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	duplicates := make([]types.DuplicateObjects, 0)
+	// the index of the duplicates by the checksums
+	index := make(map[string]int)
+	for _, object := range listResult.Objects {
+		objectInfo := object.ObjectInfo
+		if objectInfo == nil || objectInfo.ObjectStatus != storageTypes.OBJECT_STATUS_SEALED ||
+			objectInfo.PayloadSize == 0 || len(objectInfo.Checksums) == 0 {
+			continue
+		}
+
+		key := hex.EncodeToString(bytes.Join(objectInfo.Checksums, nil)) + strconv.FormatUint(objectInfo.PayloadSize, 10)
+		if i, ok := index[key]; ok {
+			duplicates[i].ObjectNames = append(duplicates[i].ObjectNames, objectInfo.ObjectName)
+			continue
+		}
+		index[key] = len(duplicates)
+		duplicates = append(duplicates, types.DuplicateObjects{
+			Checksum:    hex.EncodeToString(objectInfo.Checksums[0]),
+			PayloadSize: objectInfo.PayloadSize,
+			ObjectNames: []string{objectInfo.ObjectName},
+		})
+	}
+
+	result := make([]types.DuplicateObjects, 0)
+	for _, duplicate := range duplicates {
+		if len(duplicate.ObjectNames) > 1 {
+			result = append(result, duplicate)
+		}
+	}
+	return result, nil
+}
+
+// checksumsEqual returns true if the checksums of the object are the same as the expected ones
+func checksumsEqual(checksums, expectChecksums [][]byte) bool {
+	if len(checksums) != len(expectChecksums) {
+		return false
+	}
+	for i := range checksums {
+		if !bytes.Equal(checksums[i], expectChecksums[i]) {
+			return false
+		}
+	}
+	return true
+}
+
 // GetCreateObjectApproval returns the signature info for the approval of preCreating resources
 func (c *client) GetCreateObjectApproval(ctx context.Context, createObjectMsg *storageTypes.MsgCreateObject) (*storageTypes.MsgCreateObject, error) {
 	unsignedBytes := createObjectMsg.GetSignBytes()
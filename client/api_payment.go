@@ -2,11 +2,19 @@ package client
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	gomath "math"
+	"sort"
+	"time"
 
 	"cosmossdk.io/math"
 	gnfdSdkTypes "github.com/bnb-chain/greenfield/sdk/types"
+	spTypes "github.com/bnb-chain/greenfield/x/sp/types"
+	storageTypes "github.com/bnb-chain/greenfield/x/storage/types"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 
+	"github.com/bnb-chain/greenfield-go-sdk/types"
 	paymentTypes "github.com/bnb-chain/greenfield/x/payment/types"
 )
 
@@ -16,6 +24,17 @@ type Payment interface {
 	Deposit(ctx context.Context, toAddress string, amount math.Int, txOption gnfdSdkTypes.TxOption) (string, error)
 	Withdraw(ctx context.Context, fromAddress string, amount math.Int, txOption gnfdSdkTypes.TxOption) (string, error)
 	DisableRefund(ctx context.Context, paymentAddress string, txOption gnfdSdkTypes.TxOption) (string, error)
+
+	// EstimateCost projects the storage cost of the existing bucket and the planned uploads with the current prices,
+	// it follows the billing rules of the chain including the min charge size, the secondary SP copies and the validator tax
+	EstimateCost(ctx context.Context, opts types.EstimateCostOptions) (*types.CostEstimate, error)
+	// GetPaymentHealth computes the dynamic balance, the burn rate and the projected freezing time of the payment account,
+	// and breaks down the outflow by the buckets which are paid by it.
+	// paymentAddr indicates the HEX-encoded string of the payment account address
+	GetPaymentHealth(ctx context.Context, paymentAddr string) (*types.PaymentHealth, error)
+	// NewPaymentKeeper returns the long-running component which tops up the payment accounts from the default account
+	// whenever their runway drops below the min runway
+	NewPaymentKeeper(opts types.PaymentKeeperOptions) (*PaymentKeeper, error)
 }
 
 // GetStreamRecord retrieves stream record information for a given stream address.
@@ -83,3 +102,210 @@ func (c *client) DisableRefund(ctx context.Context, paymentAddress string, txOpt
 	}
 	return tx.TxResponse.TxHash, nil
 }
+
+// EstimateCost computes the flow rates of the bucket with the planned uploads and projects the monthly cost
+func (c *client) EstimateCost(ctx context.Context, opts types.EstimateCostOptions) (*types.CostEstimate, error) {
+	storageParams, err := c.chainClient.StorageQueryClient.Params(ctx, &storageTypes.QueryParamsRequest{})
+	if err != nil {
+		return nil, err
+	}
+	paymentParams, err := c.chainClient.PaymentQueryClient.Params(ctx, &paymentTypes.QueryParamsRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	estimate := &types.CostEstimate{
+		PrimarySPAddress: opts.PrimarySPAddress,
+		ChargedReadQuota: opts.ChargedReadQuota,
+	}
+	// the charge sizes of the secondary SPs, the rate of each one is truncated separately on chain
+	secondarySizes := make([]uint64, 0)
+	if opts.BucketName != "" {
+		bucketInfo, err := c.HeadBucket(ctx, opts.BucketName)
+		if err != nil {
+			return nil, err
+		}
+		estimate.PrimarySPAddress = bucketInfo.PrimarySpAddress
+		estimate.ChargeSize = bucketInfo.BillingInfo.TotalChargeSize
+		for _, spObjectsSize := range bucketInfo.BillingInfo.SecondarySpObjectsSize {
+			secondarySizes = append(secondarySizes, spObjectsSize.TotalChargeSize)
+			estimate.SecondaryChargeSize += spObjectsSize.TotalChargeSize
+		}
+		if estimate.ChargedReadQuota == 0 {
+			estimate.ChargedReadQuota = bucketInfo.ChargedReadQuota
+		}
+	} else if opts.PrimarySPAddress == "" {
+		return nil, errors.New("the bucket name or the primary SP address should be set")
+	}
+
+	spPrice, err := c.GetStoragePrice(ctx, estimate.PrimarySPAddress)
+	if err != nil {
+		return nil, err
+	}
+	secondaryPrice, err := c.GetSecondarySpStorePrice(ctx)
+	if err != nil {
+		return nil, err
+	}
+	estimate.ReadPrice = spPrice.ReadPrice
+	estimate.PrimaryStorePrice = spPrice.StorePrice
+	estimate.SecondaryStorePrice = secondaryPrice.StorePrice
+
+	// every piece of the data and parity shards is stored by one secondary SP which charges the full object size
+	secondaryNum := uint64(storageParams.Params.RedundantDataChunkNum + storageParams.Params.RedundantParityChunkNum)
+	lockRatePerByte := estimate.PrimaryStorePrice.Add(estimate.SecondaryStorePrice.MulInt64(int64(secondaryNum)))
+	estimate.UploadLockFee = math.ZeroInt()
+	plannedSize := uint64(0)
+	for _, size := range opts.ObjectSizes {
+		chargeSize := size
+		if chargeSize < storageParams.Params.MinChargeSize {
+			chargeSize = storageParams.Params.MinChargeSize
+		}
+		plannedSize += chargeSize
+		lockRate := lockRatePerByte.MulInt(math.NewIntFromUint64(chargeSize)).TruncateInt()
+		estimate.UploadLockFee = estimate.UploadLockFee.Add(lockRate.Mul(math.NewIntFromUint64(paymentParams.Params.ReserveTime)))
+	}
+	if plannedSize > 0 {
+		estimate.ChargeSize += plannedSize
+		estimate.SecondaryChargeSize += plannedSize * secondaryNum
+		secondarySizes = append(secondarySizes, plannedSize*secondaryNum)
+	}
+
+	estimate.ReadRate = estimate.ReadPrice.MulInt(math.NewIntFromUint64(estimate.ChargedReadQuota)).TruncateInt()
+	estimate.PrimaryStoreRate = estimate.PrimaryStorePrice.MulInt(math.NewIntFromUint64(estimate.ChargeSize)).TruncateInt()
+	estimate.SecondaryStoreRate = math.ZeroInt()
+	for _, size := range secondarySizes {
+		rate := estimate.SecondaryStorePrice.MulInt(math.NewIntFromUint64(size)).TruncateInt()
+		estimate.SecondaryStoreRate = estimate.SecondaryStoreRate.Add(rate)
+	}
+
+	outRate := estimate.ReadRate.Add(estimate.PrimaryStoreRate).Add(estimate.SecondaryStoreRate)
+	estimate.ValidatorTaxRate = paymentParams.Params.ValidatorTaxRate.MulInt(outRate).TruncateInt()
+	estimate.TotalRate = outRate.Add(estimate.ValidatorTaxRate)
+	estimate.MonthlyCost = estimate.TotalRate.Mul(math.NewInt(types.SecondsPerMonth))
+	return estimate, nil
+}
+
+// GetPaymentHealth settles the stream record to the latest block time and projects when the account runs out of balance
+func (c *client) GetPaymentHealth(ctx context.Context, paymentAddr string) (*types.PaymentHealth, error) {
+	health, err := c.getPaymentHealth(ctx, paymentAddr)
+	if err != nil {
+		return nil, err
+	}
+	health.Buckets, err = c.getBucketOutflows(ctx, paymentAddr)
+	if err != nil {
+		return nil, fmt.Errorf("fail to get the outflows of the buckets: %s", err.Error())
+	}
+	return health, nil
+}
+
+// getPaymentHealth returns the payment health without the bucket breakdown
+func (c *client) getPaymentHealth(ctx context.Context, paymentAddr string) (*types.PaymentHealth, error) {
+	streamRecord, err := c.GetStreamRecord(ctx, paymentAddr)
+	if err != nil {
+		return nil, err
+	}
+	block, err := c.GetLatestBlock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := block.Header.Time.Unix()
+
+	health := &types.PaymentHealth{
+		Account:        paymentAddr,
+		Status:         streamRecord.Status,
+		BlockTime:      block.Header.Time,
+		StaticBalance:  streamRecord.StaticBalance,
+		BufferBalance:  streamRecord.BufferBalance,
+		LockBalance:    streamRecord.LockBalance,
+		NetflowRate:    streamRecord.NetflowRate,
+		DynamicBalance: streamRecord.StaticBalance.Add(streamRecord.NetflowRate.MulRaw(now - streamRecord.CrudTimestamp)),
+		BurnRatePerDay: math.ZeroInt(),
+		OutFlows:       streamRecord.OutFlows,
+	}
+
+	if streamRecord.NetflowRate.IsNegative() {
+		burnRate := streamRecord.NetflowRate.Abs()
+		health.BurnRatePerDay = burnRate.MulRaw(types.SecondsPerDay)
+
+		// the depletion time is left nil if it is beyond the range of the unix time
+		payDuration := health.DynamicBalance.Add(streamRecord.BufferBalance).Quo(burnRate)
+		if payDuration.IsInt64() && payDuration.Int64() <= gomath.MaxInt64-now {
+			depletionTime := time.Unix(now+payDuration.Int64(), 0)
+			health.DepletionTime = &depletionTime
+		}
+		if streamRecord.SettleTimestamp > 0 {
+			settleTime := time.Unix(streamRecord.SettleTimestamp, 0)
+			health.SettleTime = &settleTime
+		}
+	}
+	health.AtRisk = streamRecord.Status == paymentTypes.STREAM_ACCOUNT_STATUS_FROZEN ||
+		(health.SettleTime != nil && health.SettleTime.Sub(health.BlockTime) < types.PaymentRiskWindow)
+	return health, nil
+}
+
+// getBucketOutflows returns the flow rates of the buckets which are paid by the payment account
+func (c *client) getBucketOutflows(ctx context.Context, paymentAddr string) ([]types.BucketOutflow, error) {
+	paymentParams, err := c.chainClient.PaymentQueryClient.Params(ctx, &paymentTypes.QueryParamsRequest{})
+	if err != nil {
+		return nil, err
+	}
+	listResult, err := c.ListBucketsByPaymentAccount(ctx, paymentAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	outflows := make([]types.BucketOutflow, 0)
+	for _, bucket := range listResult.Buckets {
+		// the billing info from chain is more recent than the one from SP
+		bucketInfo, err := c.HeadBucket(ctx, bucket.BucketInfo.BucketName)
+		if err != nil {
+			return nil, err
+		}
+		rate, err := c.getBucketFlowRate(ctx, bucketInfo, paymentParams.Params.ValidatorTaxRate)
+		if err != nil {
+			return nil, err
+		}
+		outflows = append(outflows, types.BucketOutflow{BucketName: bucketInfo.BucketName, Rate: rate})
+	}
+
+	sort.SliceStable(outflows, func(i, j int) bool {
+		return outflows[i].Rate.GT(outflows[j].Rate)
+	})
+	return outflows, nil
+}
+
+// getBucketFlowRate computes the flow rate of the bucket bill with the prices at the price time of the bucket,
+// it is consistent with the bucket bill on chain
+func (c *client) getBucketFlowRate(ctx context.Context, bucketInfo *storageTypes.BucketInfo, validatorTaxRate sdk.Dec) (math.Int, error) {
+	billingInfo := bucketInfo.BillingInfo
+	if billingInfo.TotalChargeSize == 0 && bucketInfo.ChargedReadQuota == 0 {
+		return math.ZeroInt(), nil
+	}
+
+	spAcc, err := sdk.AccAddressFromHexUnsafe(bucketInfo.PrimarySpAddress)
+	if err != nil {
+		return math.Int{}, err
+	}
+	spPriceResp, err := c.chainClient.QueryGetSpStoragePriceByTime(ctx, &spTypes.QueryGetSpStoragePriceByTimeRequest{
+		SpAddr:    spAcc.String(),
+		Timestamp: billingInfo.PriceTime,
+	})
+	if err != nil {
+		return math.Int{}, err
+	}
+	secondaryPriceResp, err := c.chainClient.QueryGetSecondarySpStorePriceByTime(ctx, &spTypes.QueryGetSecondarySpStorePriceByTimeRequest{
+		Timestamp: billingInfo.PriceTime,
+	})
+	if err != nil {
+		return math.Int{}, err
+	}
+
+	spPrice := spPriceResp.SpStoragePrice
+	rate := spPrice.ReadPrice.MulInt(math.NewIntFromUint64(bucketInfo.ChargedReadQuota)).TruncateInt()
+	rate = rate.Add(spPrice.StorePrice.MulInt(math.NewIntFromUint64(billingInfo.TotalChargeSize)).TruncateInt())
+	for _, spObjectsSize := range billingInfo.SecondarySpObjectsSize {
+		secondaryPrice := secondaryPriceResp.SecondarySpStorePrice.StorePrice
+		rate = rate.Add(secondaryPrice.MulInt(math.NewIntFromUint64(spObjectsSize.TotalChargeSize)).TruncateInt())
+	}
+	return rate.Add(validatorTaxRate.MulInt(rate).TruncateInt()), nil
+}
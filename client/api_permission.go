@@ -0,0 +1,926 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	sdkmath "cosmossdk.io/math"
+	gnfdsdk "github.com/bnb-chain/greenfield/sdk/types"
+	gnfdTypes "github.com/bnb-chain/greenfield/types"
+	"github.com/bnb-chain/greenfield/types/common"
+	"github.com/bnb-chain/greenfield/types/resource"
+	"github.com/bnb-chain/greenfield/types/s3util"
+	permTypes "github.com/bnb-chain/greenfield/x/permission/types"
+	storageTypes "github.com/bnb-chain/greenfield/x/storage/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/tx"
+	"github.com/rs/zerolog/log"
+
+	"github.com/bnb-chain/greenfield-go-sdk/types"
+)
+
+type Permission interface {
+	// ApplyPolicyDocument translates the policy document into the put policy transactions of the bucket, object and group
+	// resources, and broadcasts them one by one. It returns the txn hashes of the put policy transactions.
+	// The object resource with wildcards "*" and "?" is granted as the anchored regex sub-resource of the bucket policy.
+	ApplyPolicyDocument(ctx context.Context, doc *types.PolicyDocument, opt types.ApplyPolicyOption) ([]string, error)
+	// PlanPermissions reads the current policies of the managed principals on the managed resources and computes
+	// the grants to add, change or revoke to reach the desired state. The remaining LimitSize on chain is not compared,
+	// only adding or removing the limit of a statement is planned as a change.
+	PlanPermissions(ctx context.Context, desiredState types.PermissionState) (*types.PermissionPlan, error)
+	// ApplyPlan executes the changes of the plan in batched transactions and returns the txn hashes
+	ApplyPlan(ctx context.Context, plan *types.PermissionPlan, opt types.ApplyPlanOption) ([]string, error)
+	// ExplainPermission reports why the action on the bucket or object is allowed or denied to the user, including
+	// the ownership, the visibility, the account and group policies and the rule which decided the effect
+	ExplainPermission(ctx context.Context, userAddr, bucketName, objectName string, action permTypes.ActionType,
+		opts types.ExplainPermissionOptions) (*types.PermissionExplanation, error)
+}
+
+// policyTarget indicates the policy of one principal on one resource, which is put by one transaction
+type policyTarget struct {
+	resourceType resource.ResourceType
+	bucketName   string
+	objectName   string
+	groupName    string
+	principal    *permTypes.Principal
+	statements   []*permTypes.Statement
+}
+
+// resource returns the GRN string of the target resource
+func (t *policyTarget) resource(groupOwner sdk.AccAddress) string {
+	switch t.resourceType {
+	case resource.RESOURCE_TYPE_BUCKET:
+		return gnfdTypes.NewBucketGRN(t.bucketName).String()
+	case resource.RESOURCE_TYPE_OBJECT:
+		return gnfdTypes.NewObjectGRN(t.bucketName, t.objectName).String()
+	default:
+		return gnfdTypes.NewGroupGRN(groupOwner, t.groupName).String()
+	}
+}
+
+// ApplyPolicyDocument translates the policy document into the put policy transactions and broadcasts them
+func (c *client) ApplyPolicyDocument(ctx context.Context, doc *types.PolicyDocument, opt types.ApplyPolicyOption) ([]string, error) {
+	targets, err := c.policyTargetsFromDocument(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	// set the default txn broadcast mode as block mode
+	if opt.TxOpts == nil {
+		broadcastMode := tx.BroadcastMode_BROADCAST_MODE_BLOCK
+		opt.TxOpts = &gnfdsdk.TxOption{Mode: &broadcastMode}
+	}
+
+	putOpt := types.PutPolicyOption{TxOpts: opt.TxOpts, PolicyExpireTime: doc.ExpirationTime}
+	txnHashes := make([]string, 0, len(targets))
+	for _, target := range targets {
+		txnHash, err := c.putPolicyOfTarget(ctx, target, putOpt)
+		if err != nil {
+			return txnHashes, fmt.Errorf("fail to put policy of %s on %s: %s", target.principal.String(),
+				target.resource(c.MustGetDefaultAccount().GetAddress()), err.Error())
+		}
+		txnHashes = append(txnHashes, txnHash)
+	}
+
+	return txnHashes, nil
+}
+
+// putPolicyOfTarget sends the put policy txn of the target according to its resource type
+func (c *client) putPolicyOfTarget(ctx context.Context, target *policyTarget, opt types.PutPolicyOption) (string, error) {
+	switch target.resourceType {
+	case resource.RESOURCE_TYPE_GROUP:
+		return c.PutGroupPolicy(ctx, target.groupName, target.principal.Value, target.statements, opt)
+	default:
+		principalBytes, err := target.principal.Marshal()
+		if err != nil {
+			return "", err
+		}
+		if target.resourceType == resource.RESOURCE_TYPE_BUCKET {
+			return c.PutBucketPolicy(ctx, target.bucketName, types.Principal(principalBytes), target.statements, opt)
+		}
+		return c.PutObjectPolicy(ctx, target.bucketName, target.objectName, types.Principal(principalBytes), target.statements, opt)
+	}
+}
+
+// policyTargetsFromDocument groups the statements of the document by the resource and principal.
+// The statements which refer to the same resource and principal are merged into one policy.
+func (c *client) policyTargetsFromDocument(doc *types.PolicyDocument) ([]*policyTarget, error) {
+	if doc == nil {
+		return nil, errors.New("policy document is nil")
+	}
+
+	if err := doc.Validate(); err != nil {
+		return nil, err
+	}
+
+	sender := c.MustGetDefaultAccount().GetAddress()
+	targets := make([]*policyTarget, 0)
+	targetIndex := make(map[string]*policyTarget)
+
+	for i := range doc.Statement {
+		docStatement := &doc.Statement[i]
+		effect, _ := docStatement.PermEffect()
+		actions, _ := docStatement.PermActions()
+
+		principals, err := principalsFromPolicyPrincipal(docStatement.Principal)
+		if err != nil {
+			return nil, err
+		}
+
+		// the statements generated from this document statement, keyed by the target key and whether it is a sub-resource statement
+		generated := make(map[string]*permTypes.Statement)
+		for _, res := range docStatement.Resource {
+			target, subResource, err := parsePolicyResource(res, sender)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, principal := range principals {
+				if target.resourceType == resource.RESOURCE_TYPE_GROUP && principal.Type != permTypes.PRINCIPAL_TYPE_GNFD_ACCOUNT {
+					return nil, fmt.Errorf("group resource %s can only be granted to accounts", res)
+				}
+
+				key := policyTargetKey(target.resource(sender), principal)
+				t, ok := targetIndex[key]
+				if !ok {
+					t = &policyTarget{
+						resourceType: target.resourceType,
+						bucketName:   target.bucketName,
+						objectName:   target.objectName,
+						groupName:    target.groupName,
+						principal:    principal,
+					}
+					targetIndex[key] = t
+					targets = append(targets, t)
+				}
+
+				statementKey := fmt.Sprintf("%s|%t", key, subResource != "")
+				statement, ok := generated[statementKey]
+				if !ok {
+					statement = &permTypes.Statement{
+						Effect:         effect,
+						Actions:        actions,
+						ExpirationTime: docStatement.ExpirationTime,
+					}
+					if docStatement.LimitSize != 0 {
+						statement.LimitSize = &common.UInt64Value{Value: docStatement.LimitSize}
+					}
+					generated[statementKey] = statement
+					t.statements = append(t.statements, statement)
+				}
+				if subResource != "" {
+					statement.Resources = append(statement.Resources, subResource)
+				}
+			}
+		}
+	}
+
+	return targets, nil
+}
+
+// parsePolicyResource parses the GRN of the policy document resource into the policy target.
+// If the object name contains wildcards, the target is the bucket and the object name is converted into the regex
+// sub-resource by globToSubResource.
+func parsePolicyResource(res string, sender sdk.AccAddress) (*policyTarget, string, error) {
+	// the object name with wildcards may contain "/", which is rejected by the GRN parser
+	objectGRNPrefix := gnfdTypes.NewObjectGRN("", "").String()
+	objectGRNPrefix = strings.TrimSuffix(objectGRNPrefix, "/")
+	if strings.HasPrefix(res, objectGRNPrefix) {
+		bucketName, objectName, found := strings.Cut(strings.TrimPrefix(res, objectGRNPrefix), "/")
+		if found && strings.ContainsAny(objectName, "*?") {
+			if err := s3util.CheckValidBucketName(bucketName); err != nil {
+				return nil, "", fmt.Errorf("invalid bucket of resource %s: %s", res, err.Error())
+			}
+			subResource, err := globToSubResource(bucketName, objectName)
+			if err != nil {
+				return nil, "", fmt.Errorf("invalid object of resource %s: %s", res, err.Error())
+			}
+			return &policyTarget{resourceType: resource.RESOURCE_TYPE_BUCKET, bucketName: bucketName}, subResource, nil
+		}
+	}
+
+	var grn gnfdTypes.GRN
+	if err := grn.ParseFromString(res, true); err != nil {
+		return nil, "", err
+	}
+
+	switch grn.ResourceType() {
+	case resource.RESOURCE_TYPE_BUCKET:
+		bucketName, err := grn.GetBucketName()
+		if err != nil {
+			return nil, "", err
+		}
+		if err = s3util.CheckValidBucketName(bucketName); err != nil {
+			return nil, "", fmt.Errorf("invalid bucket of resource %s: %s", res, err.Error())
+		}
+		return &policyTarget{resourceType: resource.RESOURCE_TYPE_BUCKET, bucketName: bucketName}, "", nil
+	case resource.RESOURCE_TYPE_OBJECT:
+		bucketName, objectName, err := grn.GetBucketAndObjectName()
+		if err != nil {
+			return nil, "", err
+		}
+		if err = s3util.CheckValidBucketName(bucketName); err != nil {
+			return nil, "", fmt.Errorf("invalid bucket of resource %s: %s", res, err.Error())
+		}
+		return &policyTarget{resourceType: resource.RESOURCE_TYPE_OBJECT, bucketName: bucketName, objectName: objectName}, "", nil
+	case resource.RESOURCE_TYPE_GROUP:
+		owner, groupName, err := grn.GetGroupOwnerAndAccount()
+		if err != nil {
+			return nil, "", err
+		}
+		if !owner.Equals(sender) {
+			return nil, "", fmt.Errorf("the group policy of resource %s can only be put by the group owner %s", res, owner.String())
+		}
+		if strings.ContainsAny(groupName, "*?") {
+			return nil, "", fmt.Errorf("wildcards are not supported in the group name of resource %s", res)
+		}
+		return &policyTarget{resourceType: resource.RESOURCE_TYPE_GROUP, groupName: groupName}, "", nil
+	default:
+		return nil, "", fmt.Errorf("unsupported resource %s", res)
+	}
+}
+
+// globToSubResource converts the object name with wildcards into the sub-resource of the bucket statement.
+// The chain matches the sub-resource as an unanchored regex against the object GRN, so the object name is escaped,
+// "*" and "?" are converted into ".*" and ".", and the pattern is anchored at the end. The GRN of the sub-resource
+// should have only one "/" and no ":", so they are escaped as hex codes.
+func globToSubResource(bucketName, objectName string) (string, error) {
+	var builder strings.Builder
+	for _, r := range objectName {
+		switch r {
+		case '*':
+			builder.WriteString(".*")
+		case '?':
+			builder.WriteString(".")
+		case '/':
+			builder.WriteString(`\x2f`)
+		case ':':
+			builder.WriteString(`\x3a`)
+		default:
+			builder.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	builder.WriteString("$")
+
+	subResource := gnfdTypes.NewObjectGRN(bucketName, builder.String()).String()
+	var grn gnfdTypes.GRN
+	if err := grn.ParseFromString(subResource, true); err != nil {
+		return "", err
+	}
+	if _, err := regexp.Compile(subResource); err != nil {
+		return "", err
+	}
+	return subResource, nil
+}
+
+// PlanPermissions computes the permission changes between the current policies on chain and the desired state
+func (c *client) PlanPermissions(ctx context.Context, desiredState types.PermissionState) (*types.PermissionPlan, error) {
+	sender := c.MustGetDefaultAccount().GetAddress()
+
+	resources := make([]*policyTarget, 0)
+	resourceIndex := make(map[string]bool)
+	addResource := func(res string) error {
+		target, _, err := parsePolicyResource(res, sender)
+		if err != nil {
+			return err
+		}
+		grn := target.resource(sender)
+		if !resourceIndex[grn] {
+			resourceIndex[grn] = true
+			resources = append(resources, target)
+		}
+		return nil
+	}
+
+	principals := make([]*permTypes.Principal, 0)
+	principalIndex := make(map[string]bool)
+	addPrincipals := func(p types.PolicyPrincipal) error {
+		list, err := principalsFromPolicyPrincipal(p)
+		if err != nil {
+			return err
+		}
+		for _, principal := range list {
+			if !principalIndex[principal.String()] {
+				principalIndex[principal.String()] = true
+				principals = append(principals, principal)
+			}
+		}
+		return nil
+	}
+
+	desired := make(map[string]*policyTarget)
+	var expireTime *time.Time
+	if desiredState.Document != nil {
+		targets, err := c.policyTargetsFromDocument(desiredState.Document)
+		if err != nil {
+			return nil, err
+		}
+		for _, target := range targets {
+			desired[policyTargetKey(target.resource(sender), target.principal)] = target
+		}
+
+		for _, statement := range desiredState.Document.Statement {
+			for _, res := range statement.Resource {
+				if err = addResource(res); err != nil {
+					return nil, err
+				}
+			}
+			if err = addPrincipals(statement.Principal); err != nil {
+				return nil, err
+			}
+		}
+		expireTime = desiredState.Document.ExpirationTime
+	}
+
+	for _, res := range desiredState.Resources {
+		if err := addResource(res); err != nil {
+			return nil, err
+		}
+	}
+	if err := addPrincipals(desiredState.Principals); err != nil {
+		return nil, err
+	}
+
+	plan := &types.PermissionPlan{}
+	for _, res := range resources {
+		for _, principal := range principals {
+			// the group policy can only be granted to accounts
+			if res.resourceType == resource.RESOURCE_TYPE_GROUP && principal.Type != permTypes.PRINCIPAL_TYPE_GNFD_ACCOUNT {
+				continue
+			}
+
+			grn := res.resource(sender)
+			current, err := c.getPolicyOfTarget(ctx, res, principal)
+			if err != nil {
+				return nil, fmt.Errorf("fail to get policy of %s on %s: %s", principal.String(), grn, err.Error())
+			}
+
+			target, isDesired := desired[policyTargetKey(grn, principal)]
+			switch {
+			case !isDesired && current == nil:
+				continue
+			case !isDesired:
+				plan.Changes = append(plan.Changes, types.PermissionChange{
+					Type:      types.PermissionChangeRevoke,
+					Resource:  grn,
+					Principal: principal,
+					Current:   current,
+				})
+			case current == nil:
+				plan.Changes = append(plan.Changes, types.PermissionChange{
+					Type:           types.PermissionChangeAdd,
+					Resource:       grn,
+					Principal:      principal,
+					Statements:     target.statements,
+					ExpirationTime: expireTime,
+				})
+			case policyEqual(current, target.statements, expireTime):
+				plan.Unchanged++
+			default:
+				plan.Changes = append(plan.Changes, types.PermissionChange{
+					Type:           types.PermissionChangeUpdate,
+					Resource:       grn,
+					Principal:      principal,
+					Current:        current,
+					Statements:     target.statements,
+					ExpirationTime: expireTime,
+				})
+			}
+		}
+	}
+
+	return plan, nil
+}
+
+// ApplyPlan executes the changes of the plan in batched transactions, the put policy msg is sent for the added and
+// changed grants and the delete policy msg is sent for the revoked grants
+func (c *client) ApplyPlan(ctx context.Context, plan *types.PermissionPlan, opt types.ApplyPlanOption) ([]string, error) {
+	if plan == nil || plan.IsEmpty() {
+		return nil, nil
+	}
+
+	batchSize := opt.BatchSize
+	if batchSize <= 0 {
+		batchSize = types.DefaultPermissionBatchSize
+	}
+
+	// set the default txn broadcast mode as block mode
+	if opt.TxOpts == nil {
+		broadcastMode := tx.BroadcastMode_BROADCAST_MODE_BLOCK
+		opt.TxOpts = &gnfdsdk.TxOption{Mode: &broadcastMode}
+	}
+
+	operator := c.MustGetDefaultAccount().GetAddress()
+	msgs := make([]sdk.Msg, 0, len(plan.Changes))
+	for _, change := range plan.Changes {
+		var msg sdk.Msg
+		if change.Type == types.PermissionChangeRevoke {
+			msg = storageTypes.NewMsgDeletePolicy(operator, change.Resource, change.Principal)
+		} else {
+			msg = storageTypes.NewMsgPutPolicy(operator, change.Resource, change.Principal, change.Statements, change.ExpirationTime)
+		}
+
+		if err := msg.ValidateBasic(); err != nil {
+			return nil, fmt.Errorf("invalid change %s: %s", change.String(), err.Error())
+		}
+		msgs = append(msgs, msg)
+	}
+
+	txnHashes := make([]string, 0, (len(msgs)+batchSize-1)/batchSize)
+	for start := 0; start < len(msgs); start += batchSize {
+		end := start + batchSize
+		if end > len(msgs) {
+			end = len(msgs)
+		}
+
+		resp, err := c.chainClient.BroadcastTx(ctx, msgs[start:end], opt.TxOpts)
+		if err != nil {
+			return txnHashes, err
+		}
+		txnHashes = append(txnHashes, resp.TxResponse.TxHash)
+	}
+
+	return txnHashes, nil
+}
+
+// getPolicyOfTarget returns the policy of the principal on the target resource, it returns nil if the policy not exists
+func (c *client) getPolicyOfTarget(ctx context.Context, target *policyTarget, principal *permTypes.Principal) (*permTypes.Policy, error) {
+	var policy *permTypes.Policy
+	var err error
+
+	if principal.Type == permTypes.PRINCIPAL_TYPE_GNFD_GROUP {
+		groupId := principal.MustGetGroupID().Uint64()
+		if target.resourceType == resource.RESOURCE_TYPE_BUCKET {
+			policy, err = c.GetBucketPolicyOfGroup(ctx, target.bucketName, groupId)
+		} else {
+			policy, err = c.GetObjectPolicyOfGroup(ctx, target.bucketName, target.objectName, groupId)
+		}
+	} else {
+		switch target.resourceType {
+		case resource.RESOURCE_TYPE_BUCKET:
+			policy, err = c.GetBucketPolicy(ctx, target.bucketName, principal.Value)
+		case resource.RESOURCE_TYPE_OBJECT:
+			policy, err = c.GetObjectPolicy(ctx, target.bucketName, target.objectName, principal.Value)
+		default:
+			var resp *storageTypes.QueryPolicyForAccountResponse
+			resp, err = c.chainClient.QueryPolicyForAccount(ctx, &storageTypes.QueryPolicyForAccountRequest{
+				Resource:         target.resource(c.MustGetDefaultAccount().GetAddress()),
+				PrincipalAddress: principal.Value,
+			})
+			if err == nil {
+				policy = resp.Policy
+			}
+		}
+	}
+
+	if err != nil {
+		if isNoSuchPolicyErr(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return policy, nil
+}
+
+// isNoSuchPolicyErr returns true if the error indicates the policy not exists on chain
+func isNoSuchPolicyErr(err error) bool {
+	return strings.Contains(err.Error(), storageTypes.ErrNoSuchPolicy.Error())
+}
+
+// policyEqual returns true if the policy on chain has the same statements and expiration time as the desired one.
+// The remaining size of LimitSize on chain is decreased by the created objects, so only whether the statement is
+// limited is compared, otherwise an unchanged policy would be updated and its used quota reset after any upload.
+// Delete and put the policy again to reset the quota.
+func policyEqual(current *permTypes.Policy, statements []*permTypes.Statement, expireTime *time.Time) bool {
+	if (current.ExpirationTime == nil) != (expireTime == nil) {
+		return false
+	}
+	if expireTime != nil && !current.ExpirationTime.Equal(*expireTime) {
+		return false
+	}
+
+	if len(current.Statements) != len(statements) {
+		return false
+	}
+	for i := range statements {
+		if (current.Statements[i].LimitSize == nil) != (statements[i].LimitSize == nil) {
+			return false
+		}
+		currentBytes, err := marshalStatementWithoutLimit(current.Statements[i])
+		if err != nil {
+			return false
+		}
+		desiredBytes, err := marshalStatementWithoutLimit(statements[i])
+		if err != nil {
+			return false
+		}
+		if !bytes.Equal(currentBytes, desiredBytes) {
+			return false
+		}
+	}
+	return true
+}
+
+// marshalStatementWithoutLimit marshals a copy of the statement without LimitSize
+func marshalStatementWithoutLimit(statement *permTypes.Statement) ([]byte, error) {
+	statementCopy := *statement
+	statementCopy.LimitSize = nil
+	return statementCopy.Marshal()
+}
+
+// principalsFromPolicyPrincipal converts the principal of the policy document into the principals of permission module
+func principalsFromPolicyPrincipal(p types.PolicyPrincipal) ([]*permTypes.Principal, error) {
+	principals := make([]*permTypes.Principal, 0, len(p.Account)+len(p.Group))
+	for _, addr := range p.Account {
+		accAddr, err := sdk.AccAddressFromHexUnsafe(addr)
+		if err != nil {
+			return nil, err
+		}
+		principals = append(principals, permTypes.NewPrincipalWithAccount(accAddr))
+	}
+	for _, groupId := range p.Group {
+		if groupId == 0 {
+			return nil, errors.New("invalid principal group id 0")
+		}
+		principals = append(principals, permTypes.NewPrincipalWithGroup(sdkmath.NewUint(groupId)))
+	}
+	return principals, nil
+}
+
+func policyTargetKey(grn string, principal *permTypes.Principal) string {
+	return grn + "|" + principal.String()
+}
+
+var (
+	// publicReadBucketActions and publicReadObjectActions are the actions allowed to everyone on the public-read
+	// resources, they are consistent with the permission verification of greenfield chain
+	publicReadBucketActions = map[permTypes.ActionType]bool{
+		permTypes.ACTION_GET_OBJECT:     true,
+		permTypes.ACTION_COPY_OBJECT:    true,
+		permTypes.ACTION_EXECUTE_OBJECT: true,
+		permTypes.ACTION_LIST_OBJECT:    true,
+	}
+	publicReadObjectActions = map[permTypes.ActionType]bool{
+		permTypes.ACTION_GET_OBJECT:     true,
+		permTypes.ACTION_COPY_OBJECT:    true,
+		permTypes.ACTION_EXECUTE_OBJECT: true,
+	}
+)
+
+// ExplainPermission reproduces the permission verification of the chain step by step and reports which rule decided
+// the effect of the action. If objectName is empty, the bucket permission is explained.
+func (c *client) ExplainPermission(ctx context.Context, userAddr, bucketName, objectName string,
+	action permTypes.ActionType, opts types.ExplainPermissionOptions,
+) (*types.PermissionExplanation, error) {
+	user, err := sdk.AccAddressFromHexUnsafe(userAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	bucketInfo, err := c.HeadBucket(ctx, bucketName)
+	if err != nil {
+		return nil, fmt.Errorf("fail to head bucket %s: %s", bucketName, err.Error())
+	}
+	var objectInfo *storageTypes.ObjectInfo
+	if objectName != "" {
+		objectInfo, err = c.HeadObject(ctx, bucketName, objectName)
+		if err != nil {
+			return nil, fmt.Errorf("fail to head object %s: %s", objectName, err.Error())
+		}
+	}
+
+	block, err := c.GetLatestBlock(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	explanation := &types.PermissionExplanation{
+		User:             user.String(),
+		BucketName:       bucketName,
+		ObjectName:       objectName,
+		Action:           action,
+		BlockTime:        block.Header.Time,
+		Owner:            bucketInfo.Owner,
+		BucketVisibility: bucketInfo.Visibility,
+	}
+
+	groups := opts.Groups
+	if len(groups) == 0 {
+		groups = c.groupsOfMember(ctx, user.String())
+	}
+	explanation.Groups, err = c.groupMemberships(ctx, user, groups)
+	if err != nil {
+		return nil, err
+	}
+
+	if objectInfo == nil {
+		err = c.explainBucketPermission(ctx, explanation, bucketInfo, opts.WantedSize)
+	} else {
+		err = c.explainObjectPermission(ctx, explanation, bucketInfo, objectInfo)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if objectInfo == nil {
+		explanation.ChainEffect, err = c.IsBucketPermissionAllowed(ctx, userAddr, bucketName, action)
+	} else {
+		explanation.ChainEffect, err = c.IsObjectPermissionAllowed(ctx, userAddr, bucketName, objectName, action)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("fail to verify permission on chain: %s", err.Error())
+	}
+
+	return explanation, nil
+}
+
+// groupsOfMember discovers the groups which the member belongs to from SP, the policies of these groups
+// are checked if the groups are not specified. It returns nil if the SP metadata service is unavailable.
+func (c *client) groupsOfMember(ctx context.Context, memberAddr string) []types.GroupIdentity {
+	groups := make([]types.GroupIdentity, 0)
+	opts := types.ListGroupsForMemberOptions{Limit: types.DefaultListGroupsLimit}
+	for {
+		result, err := c.ListGroupsForMember(ctx, memberAddr, opts)
+		if err != nil {
+			log.Error().Msg(fmt.Sprintf("fail to list groups of member %s, group policies are skipped: %s", memberAddr, err.Error()))
+			return nil
+		}
+		for _, group := range result.Groups {
+			if group.Removed {
+				continue
+			}
+			groups = append(groups, types.GroupIdentity{GroupOwner: group.Group.Owner, GroupName: group.Group.GroupName})
+		}
+		if int64(len(result.Groups)) < opts.Limit {
+			return groups
+		}
+		opts.StartAfter = result.Groups[len(result.Groups)-1].Group.Id.String()
+	}
+}
+
+// groupMemberships checks the membership of the user in the groups
+func (c *client) groupMemberships(ctx context.Context, user sdk.AccAddress, groups []types.GroupIdentity) ([]types.GroupMembership, error) {
+	memberships := make([]types.GroupMembership, 0, len(groups))
+	for _, group := range groups {
+		groupInfo, err := c.HeadGroup(ctx, group.GroupName, group.GroupOwner)
+		if err != nil {
+			return nil, fmt.Errorf("fail to head group %s of owner %s: %s", group.GroupName, group.GroupOwner, err.Error())
+		}
+		memberships = append(memberships, types.GroupMembership{
+			GroupIdentity: group,
+			GroupId:       groupInfo.Id.Uint64(),
+			IsMember:      c.HeadGroupMember(ctx, group.GroupName, group.GroupOwner, user.String()),
+		})
+	}
+	return memberships, nil
+}
+
+// explainBucketPermission follows the order of the bucket permission verification:
+// public-read visibility, ownership and the policies of the bucket
+func (c *client) explainBucketPermission(ctx context.Context, explanation *types.PermissionExplanation,
+	bucketInfo *storageTypes.BucketInfo, wantedSize uint64,
+) error {
+	if bucketInfo.Visibility == storageTypes.VISIBILITY_TYPE_PUBLIC_READ && publicReadBucketActions[explanation.Action] {
+		explanation.IsPublicRead = true
+		decide(explanation, permTypes.EFFECT_ALLOW, "public-read bucket visibility",
+			"the bucket is public-read and the action is allowed to everyone")
+		return nil
+	}
+	explanation.Steps = append(explanation.Steps, fmt.Sprintf("the bucket visibility is %s, the action is not allowed to everyone",
+		bucketInfo.Visibility.String()))
+
+	if explanation.User == bucketInfo.Owner {
+		explanation.IsOwner = true
+		decide(explanation, permTypes.EFFECT_ALLOW, "bucket ownership", "the user is the owner of the bucket")
+		return nil
+	}
+	explanation.Steps = append(explanation.Steps, fmt.Sprintf("the user is not the owner of the bucket, the owner is %s", bucketInfo.Owner))
+
+	var verifyOpts *permTypes.VerifyOptions
+	if wantedSize != 0 {
+		verifyOpts = &permTypes.VerifyOptions{WantedSize: &wantedSize}
+	}
+	target := &policyTarget{resourceType: resource.RESOURCE_TYPE_BUCKET, bucketName: bucketInfo.BucketName}
+	effect, rule, err := c.explainPolicies(ctx, explanation, target, verifyOpts)
+	if err != nil {
+		return err
+	}
+	if effect == permTypes.EFFECT_ALLOW {
+		decide(explanation, permTypes.EFFECT_ALLOW, rule, "the bucket policy allows the action")
+		return nil
+	}
+	if effect == permTypes.EFFECT_DENY {
+		decide(explanation, permTypes.EFFECT_DENY, rule, "the bucket policy denies the action")
+		return nil
+	}
+	decide(explanation, permTypes.EFFECT_DENY, "default deny", "no policy statement matches the action")
+	return nil
+}
+
+// explainObjectPermission follows the order of the object permission verification: public-read visibility,
+// ownership, the policies of the bucket with the object as sub-resource and the policies of the object
+func (c *client) explainObjectPermission(ctx context.Context, explanation *types.PermissionExplanation,
+	bucketInfo *storageTypes.BucketInfo, objectInfo *storageTypes.ObjectInfo,
+) error {
+	explanation.Owner = objectInfo.Owner
+	explanation.ObjectVisibility = objectInfo.Visibility
+
+	isPublic := objectInfo.Visibility == storageTypes.VISIBILITY_TYPE_PUBLIC_READ ||
+		(objectInfo.Visibility == storageTypes.VISIBILITY_TYPE_INHERIT && bucketInfo.Visibility == storageTypes.VISIBILITY_TYPE_PUBLIC_READ)
+	if isPublic && publicReadObjectActions[explanation.Action] {
+		explanation.IsPublicRead = true
+		decide(explanation, permTypes.EFFECT_ALLOW, "public-read object visibility",
+			fmt.Sprintf("the object visibility is %s with bucket visibility %s, the action is allowed to everyone",
+				objectInfo.Visibility.String(), bucketInfo.Visibility.String()))
+		return nil
+	}
+	explanation.Steps = append(explanation.Steps, fmt.Sprintf("the object visibility is %s with bucket visibility %s, the action is not allowed to everyone",
+		objectInfo.Visibility.String(), bucketInfo.Visibility.String()))
+
+	if explanation.User == objectInfo.Owner {
+		explanation.IsOwner = true
+		decide(explanation, permTypes.EFFECT_ALLOW, "object ownership", "the user is the owner of the object")
+		return nil
+	}
+	explanation.Steps = append(explanation.Steps, fmt.Sprintf("the user is not the owner of the object, the owner is %s", objectInfo.Owner))
+
+	bucketTarget := &policyTarget{resourceType: resource.RESOURCE_TYPE_BUCKET, bucketName: bucketInfo.BucketName}
+	verifyOpts := &permTypes.VerifyOptions{Resource: gnfdTypes.NewObjectGRN(objectInfo.BucketName, objectInfo.ObjectName).String()}
+	bucketEffect, bucketRule, err := c.explainPolicies(ctx, explanation, bucketTarget, verifyOpts)
+	if err != nil {
+		return err
+	}
+	if bucketEffect == permTypes.EFFECT_DENY {
+		decide(explanation, permTypes.EFFECT_DENY, bucketRule, "the bucket policy denies the action on the object")
+		return nil
+	}
+
+	objectTarget := &policyTarget{resourceType: resource.RESOURCE_TYPE_OBJECT, bucketName: objectInfo.BucketName, objectName: objectInfo.ObjectName}
+	objectEffect, objectRule, err := c.explainPolicies(ctx, explanation, objectTarget, nil)
+	if err != nil {
+		return err
+	}
+	switch {
+	case objectEffect == permTypes.EFFECT_DENY:
+		decide(explanation, permTypes.EFFECT_DENY, objectRule, "the object policy denies the action")
+	case bucketEffect == permTypes.EFFECT_ALLOW:
+		decide(explanation, permTypes.EFFECT_ALLOW, bucketRule, "the bucket policy allows the action on the object")
+	case objectEffect == permTypes.EFFECT_ALLOW:
+		decide(explanation, permTypes.EFFECT_ALLOW, objectRule, "the object policy allows the action")
+	default:
+		decide(explanation, permTypes.EFFECT_DENY, "default deny", "no policy statement matches the action")
+	}
+	return nil
+}
+
+// explainPolicies evaluates the account policy and the group policies on the target resource as the chain does:
+// the account policy takes precedence, then any group policy of the member groups denies or allows.
+// It returns the effect and the description of the deciding policy.
+func (c *client) explainPolicies(ctx context.Context, explanation *types.PermissionExplanation,
+	target *policyTarget, verifyOpts *permTypes.VerifyOptions,
+) (permTypes.Effect, string, error) {
+	res := target.resource(nil)
+
+	accountPrincipal := permTypes.NewPrincipalWithAccount(sdk.MustAccAddressFromHex(explanation.User))
+	accountEval, err := c.evalPolicyOfTarget(ctx, explanation, target, accountPrincipal, verifyOpts)
+	if err != nil {
+		return permTypes.EFFECT_UNSPECIFIED, "", err
+	}
+	explanation.Policies = append(explanation.Policies, accountEval)
+	if !accountEval.Found {
+		explanation.Steps = append(explanation.Steps, fmt.Sprintf("no policy of the user on %s", res))
+	} else {
+		explanation.Steps = append(explanation.Steps, describePolicyEvaluation(accountEval, "the policy of the user"))
+	}
+	if accountEval.Effect != permTypes.EFFECT_UNSPECIFIED {
+		return accountEval.Effect, fmt.Sprintf("policy %s of the user on %s", accountEval.PolicyId, res), nil
+	}
+
+	var allowedBy string
+	for i := range explanation.Groups {
+		membership := explanation.Groups[i]
+		groupPrincipal := permTypes.NewPrincipalWithGroup(sdkmath.NewUint(membership.GroupId))
+		groupEval, err := c.evalPolicyOfTarget(ctx, explanation, target, groupPrincipal, verifyOpts)
+		if err != nil {
+			return permTypes.EFFECT_UNSPECIFIED, "", err
+		}
+		groupEval.Group = &membership
+		explanation.Policies = append(explanation.Policies, groupEval)
+		if !groupEval.Found {
+			continue
+		}
+
+		groupDesc := fmt.Sprintf("the policy of group %s(id: %d)", membership.GroupName, membership.GroupId)
+		if !membership.IsMember {
+			explanation.Steps = append(explanation.Steps, describePolicyEvaluation(groupEval, groupDesc)+
+				", ignored since the user is not a member")
+			continue
+		}
+		explanation.Steps = append(explanation.Steps, describePolicyEvaluation(groupEval, groupDesc))
+
+		rule := fmt.Sprintf("policy %s of group %s(id: %d) on %s", groupEval.PolicyId, membership.GroupName, membership.GroupId, res)
+		if groupEval.Effect == permTypes.EFFECT_DENY {
+			return permTypes.EFFECT_DENY, rule, nil
+		}
+		if groupEval.Effect == permTypes.EFFECT_ALLOW && allowedBy == "" {
+			allowedBy = rule
+		}
+	}
+	if allowedBy != "" {
+		return permTypes.EFFECT_ALLOW, allowedBy, nil
+	}
+	return permTypes.EFFECT_UNSPECIFIED, "", nil
+}
+
+// evalPolicyOfTarget queries the policy of the principal on the target resource and evaluates every statement of it
+func (c *client) evalPolicyOfTarget(ctx context.Context, explanation *types.PermissionExplanation, target *policyTarget,
+	principal *permTypes.Principal, verifyOpts *permTypes.VerifyOptions,
+) (types.PolicyEvaluation, error) {
+	evaluation := types.PolicyEvaluation{
+		Resource:          target.resource(nil),
+		Principal:         principal,
+		Effect:            permTypes.EFFECT_UNSPECIFIED,
+		DecisiveStatement: -1,
+	}
+
+	policy, err := c.getPolicyOfTarget(ctx, target, principal)
+	if err != nil {
+		return evaluation, fmt.Errorf("fail to get policy of %s on %s: %s", principal.String(), evaluation.Resource, err.Error())
+	}
+	if policy == nil {
+		return evaluation, nil
+	}
+	evaluation.Found = true
+	evaluation.PolicyId = policy.Id.String()
+
+	if policy.ExpirationTime != nil && policy.ExpirationTime.Before(explanation.BlockTime) {
+		evaluation.Expired = true
+		return evaluation, nil
+	}
+
+	allowed := -1
+	for i, s := range policy.Statements {
+		statementEval := types.StatementEvaluation{Index: i, Statement: s, Result: permTypes.EFFECT_UNSPECIFIED}
+		if s.ExpirationTime != nil && s.ExpirationTime.Before(explanation.BlockTime) {
+			statementEval.Expired = true
+			evaluation.Statements = append(evaluation.Statements, statementEval)
+			continue
+		}
+		// evaluate a copy since the evaluation of CreateObject updates the limit size of the statement
+		statement := *s
+		statementEval.Result, _ = statement.Eval(explanation.Action, verifyOpts)
+		evaluation.Statements = append(evaluation.Statements, statementEval)
+
+		if statementEval.Result == permTypes.EFFECT_DENY && evaluation.Effect != permTypes.EFFECT_DENY {
+			evaluation.Effect = permTypes.EFFECT_DENY
+			evaluation.DecisiveStatement = i
+		}
+		if statementEval.Result == permTypes.EFFECT_ALLOW && allowed < 0 {
+			allowed = i
+		}
+	}
+	if evaluation.Effect != permTypes.EFFECT_DENY && allowed >= 0 {
+		evaluation.Effect = permTypes.EFFECT_ALLOW
+		evaluation.DecisiveStatement = allowed
+	}
+	return evaluation, nil
+}
+
+// describePolicyEvaluation returns the readable description of the evaluation result of the policy
+func describePolicyEvaluation(evaluation types.PolicyEvaluation, name string) string {
+	desc := fmt.Sprintf("%s(id: %s) on %s", name, evaluation.PolicyId, evaluation.Resource)
+	if evaluation.Expired {
+		return desc + " is expired"
+	}
+
+	var notes []string
+	for _, s := range evaluation.Statements {
+		if s.Expired {
+			notes = append(notes, fmt.Sprintf("statement %d is expired at %s", s.Index, s.Statement.ExpirationTime.String()))
+		}
+		if s.Statement.LimitSize != nil {
+			notes = append(notes, fmt.Sprintf("statement %d limits the size to %d", s.Index, s.Statement.LimitSize.GetValue()))
+		}
+	}
+	if evaluation.DecisiveStatement >= 0 {
+		desc += fmt.Sprintf(" evaluates to %s by statement %d", evaluation.Effect.String(), evaluation.DecisiveStatement)
+	} else {
+		desc += " has no statement matching the action"
+	}
+	if len(notes) > 0 {
+		desc += " (" + strings.Join(notes, "; ") + ")"
+	}
+	return desc
+}
+
+// decide records the final effect and the deciding rule of the explanation
+func decide(explanation *types.PermissionExplanation, effect permTypes.Effect, rule string, step string) {
+	explanation.Effect = effect
+	explanation.DecidedBy = rule
+	explanation.Steps = append(explanation.Steps, step)
+}
@@ -21,10 +21,25 @@ type SP interface {
 	// ListStorageProviders return the storage provider info on chain
 	// isInService indicates if only display the sp with STATUS_IN_SERVICE status
 	ListStorageProviders(ctx context.Context, isInService bool) ([]spTypes.StorageProvider, error)
+	// SelectStorageProviders returns at most n in-service SPs in the order of preference of the SP selector of the client
+	SelectStorageProviders(ctx context.Context, n int) ([]spTypes.StorageProvider, error)
+	// NewSPHealthChecker returns the checker which probes the SPs and tracks their health,
+	// it is used by the client only after being installed by SetSPHealthChecker
+	NewSPHealthChecker(opts types.SPHealthCheckOptions) *SPHealthChecker
+	// SetSPHealthChecker installs the SP health checker, the requests of the bucket fail fast once its primary SP
+	// is known to be unhealthy by the checker. The nil checker stops checking the SP health.
+	SetSPHealthChecker(checker *SPHealthChecker)
 	// GetStorageProviderInfo return the sp info with the sp chain address
 	GetStorageProviderInfo(ctx context.Context, SPAddr sdk.AccAddress) (*spTypes.StorageProvider, error)
 	// GetStoragePrice returns the storage price for a particular storage provider, including update time, read price, store price and .etc.
 	GetStoragePrice(ctx context.Context, SPAddr string) (*spTypes.SpStoragePrice, error)
+	// GetStoragePriceAt returns the storage price of the SP which is in effect at the time
+	GetStoragePriceAt(ctx context.Context, spAddr string, t time.Time) (*spTypes.SpStoragePrice, error)
+	// GetStoragePriceHistory returns the storage prices of the SP in effect during the period in the order of the update time,
+	// the first one is the price in effect at the start time
+	GetStoragePriceHistory(ctx context.Context, spAddr string, from, to time.Time) ([]*spTypes.SpStoragePrice, error)
+	// NewStoragePriceWatcher returns the watcher which notifies the read or store price changes of the SPs
+	NewStoragePriceWatcher(opts types.StoragePriceWatchOptions) (*StoragePriceWatcher, error)
 	// GetSecondarySpStorePrice returns the secondary storage price, including update time and store price
 	GetSecondarySpStorePrice(ctx context.Context) (*spTypes.SecondarySpStorePrice, error)
 	// GrantDepositForStorageProvider submit a grant transaction to allow gov module account to deduct the specified number of tokens
@@ -50,6 +65,63 @@ func (c *client) GetStoragePrice(ctx context.Context, spAddr string) (*spTypes.S
 	return &resp.SpStoragePrice, nil
 }
 
+// GetStoragePriceAt returns the latest price of the SP updated no later than the time
+func (c *client) GetStoragePriceAt(ctx context.Context, spAddr string, t time.Time) (*spTypes.SpStoragePrice, error) {
+	spAcc, err := sdk.AccAddressFromHexUnsafe(spAddr)
+	if err != nil {
+		return nil, err
+	}
+	return c.getStoragePriceBefore(ctx, spAcc, t.Unix()+1)
+}
+
+// GetStoragePriceHistory walks the price updates of the SP backward from the end time until the price in effect at the start time,
+// at most MaxStoragePriceHistory prices are returned
+func (c *client) GetStoragePriceHistory(ctx context.Context, spAddr string, from, to time.Time) ([]*spTypes.SpStoragePrice, error) {
+	if to.Before(from) {
+		return nil, errors.New("the end time should not be earlier than the start time")
+	}
+	spAcc, err := sdk.AccAddressFromHexUnsafe(spAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	history := make([]*spTypes.SpStoragePrice, 0)
+	before := to.Unix() + 1
+	for len(history) < types.MaxStoragePriceHistory {
+		price, err := c.getStoragePriceBefore(ctx, spAcc, before)
+		if err != nil {
+			// no older price exists
+			if len(history) > 0 && strings.Contains(err.Error(), "not found") {
+				break
+			}
+			return nil, err
+		}
+		history = append(history, price)
+		if price.UpdateTimeSec <= from.Unix() || price.UpdateTimeSec <= 0 || price.UpdateTimeSec >= before {
+			break
+		}
+		before = price.UpdateTimeSec
+	}
+
+	// in the order of the update time
+	for i, j := 0, len(history)-1; i < j; i, j = i+1, j-1 {
+		history[i], history[j] = history[j], history[i]
+	}
+	return history, nil
+}
+
+// getStoragePriceBefore returns the latest price of the SP updated before the unix timestamp
+func (c *client) getStoragePriceBefore(ctx context.Context, spAcc sdk.AccAddress, timestamp int64) (*spTypes.SpStoragePrice, error) {
+	resp, err := c.chainClient.QueryGetSpStoragePriceByTime(ctx, &spTypes.QueryGetSpStoragePriceByTimeRequest{
+		SpAddr:    spAcc.String(),
+		Timestamp: timestamp,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &resp.SpStoragePrice, nil
+}
+
 func (c *client) GetSecondarySpStorePrice(ctx context.Context) (*spTypes.SecondarySpStorePrice, error) {
 	resp, err := c.chainClient.QueryGetSecondarySpStorePriceByTime(ctx, &spTypes.QueryGetSecondarySpStorePriceByTimeRequest{
 		Timestamp: 0,
@@ -81,6 +153,11 @@ func (c *client) ListStorageProviders(ctx context.Context, isInService bool) ([]
 	return spInfoList, nil
 }
 
+// SelectStorageProviders returns the in-service SPs chosen by the SP selector of the client
+func (c *client) SelectStorageProviders(ctx context.Context, n int) ([]spTypes.StorageProvider, error) {
+	return c.selectSPs(ctx, n)
+}
+
 // GetStorageProviderInfo return the sp info with the sp chain address
 func (c *client) GetStorageProviderInfo(ctx context.Context, SPAddr sdk.AccAddress) (*spTypes.StorageProvider, error) {
 	request := &spTypes.QueryStorageProviderRequest{
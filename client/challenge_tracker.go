@@ -0,0 +1,213 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	gnfdsdktypes "github.com/bnb-chain/greenfield/sdk/types"
+	challengetypes "github.com/bnb-chain/greenfield/x/challenge/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/bnb-chain/greenfield-go-sdk/types"
+)
+
+// ChallengeTracker submits the challenges from the default account of the client and tracks them until they are
+// attested or expired
+type ChallengeTracker struct {
+	client *client
+	opts   types.ChallengeTrackerOptions
+
+	mu         sync.Mutex
+	challenges map[uint64]*types.TrackedChallenge
+}
+
+// NewChallengeTracker returns the tracker of the challenges submitted by the default account
+func (c *client) NewChallengeTracker(opts types.ChallengeTrackerOptions) *ChallengeTracker {
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = types.DefaultChallengePollInterval
+	}
+	return &ChallengeTracker{
+		client:     c,
+		opts:       opts,
+		challenges: make(map[uint64]*types.TrackedChallenge),
+	}
+}
+
+// Submit submits the challenge of the object piece stored on the SP and tracks the challenge ID parsed from the tx events.
+// The tx is waited for until the context is done, the returned challenge carries the txn hash if the tx is broadcast
+// but not found, so that it can be tracked later by TrackTx.
+func (t *ChallengeTracker) Submit(ctx context.Context, spOperatorAddress, bucketName, objectName string, randomIndex bool,
+	segmentIndex uint32, txOption gnfdsdktypes.TxOption,
+) (types.TrackedChallenge, error) {
+	challenger := t.client.MustGetDefaultAccount().GetAddress().String()
+	resp, err := t.client.SubmitChallenge(ctx, challenger, spOperatorAddress, bucketName, objectName, randomIndex,
+		segmentIndex, txOption)
+	if err != nil {
+		return types.TrackedChallenge{}, err
+	}
+	return t.TrackTx(ctx, resp.TxHash)
+}
+
+// TrackTx waits for the submit challenge tx and tracks the challenge parsed from the tx events
+func (t *ChallengeTracker) TrackTx(ctx context.Context, txnHash string) (types.TrackedChallenge, error) {
+	pending := types.TrackedChallenge{
+		ChallengerAddress: t.client.MustGetDefaultAccount().GetAddress().String(),
+		TxnHash:           txnHash,
+		SubmitTime:        time.Now(),
+		Status:            types.ChallengePending,
+	}
+	txResp, err := t.client.WaitForTx(ctx, txnHash)
+	if err != nil {
+		return pending, fmt.Errorf("fail to wait for the challenge tx %s: %s", txnHash, err.Error())
+	}
+	if txResp.Code != 0 {
+		return pending, fmt.Errorf("fail to submit challenge in tx %s: %s", txnHash, txResp.RawLog)
+	}
+	challenge, err := parseStartChallengeEvent(txResp, txnHash)
+	if err != nil {
+		return pending, err
+	}
+
+	t.mu.Lock()
+	t.challenges[challenge.ChallengeId] = challenge
+	t.mu.Unlock()
+	return *challenge, nil
+}
+
+// Track adds the challenge submitted elsewhere to the tracker
+func (t *ChallengeTracker) Track(challenge types.TrackedChallenge) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.challenges[challenge.ChallengeId] = &challenge
+}
+
+// Refresh queries the latest attested challenges and the block height, and updates the status of the pending challenges,
+// it returns the challenges whose status changed
+func (t *ChallengeTracker) Refresh(ctx context.Context) ([]types.TrackedChallenge, error) {
+	attestedIds, err := t.client.LatestAttestedChallenges(ctx, &challengetypes.QueryLatestAttestedChallengesRequest{})
+	if err != nil {
+		return nil, err
+	}
+	height, err := t.client.GetLatestBlockHeight(ctx)
+	if err != nil {
+		return nil, err
+	}
+	attested := make(map[uint64]bool, len(attestedIds))
+	for _, id := range attestedIds {
+		attested[id] = true
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	changed := make([]types.TrackedChallenge, 0)
+	for id, challenge := range t.challenges {
+		if challenge.Status != types.ChallengePending {
+			continue
+		}
+		if attested[id] {
+			challenge.Status = types.ChallengeAttested
+		} else if challenge.ExpiredHeight > 0 && uint64(height) > challenge.ExpiredHeight {
+			challenge.Status = types.ChallengeExpired
+		} else {
+			continue
+		}
+		changed = append(changed, *challenge)
+	}
+	sortChallenges(changed)
+	return changed, nil
+}
+
+// WaitForAttestation polls the status of the tracked challenge until it is attested or expired
+func (t *ChallengeTracker) WaitForAttestation(ctx context.Context, challengeId uint64) (types.TrackedChallenge, error) {
+	ticker := time.NewTicker(t.opts.PollInterval)
+	defer ticker.Stop()
+	for {
+		t.mu.Lock()
+		challenge, ok := t.challenges[challengeId]
+		var current types.TrackedChallenge
+		if ok {
+			current = *challenge
+		}
+		t.mu.Unlock()
+		if !ok {
+			return types.TrackedChallenge{}, fmt.Errorf("the challenge %d is not tracked", challengeId)
+		}
+		if current.Status != types.ChallengePending {
+			return current, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return current, ctx.Err()
+		case <-ticker.C:
+		}
+		if _, err := t.Refresh(ctx); err != nil {
+			return current, err
+		}
+	}
+}
+
+// Challenges returns the tracked challenges ordered by the challenge ID
+func (t *ChallengeTracker) Challenges() []types.TrackedChallenge {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	challenges := make([]types.TrackedChallenge, 0, len(t.challenges))
+	for _, challenge := range t.challenges {
+		challenges = append(challenges, *challenge)
+	}
+	sortChallenges(challenges)
+	return challenges
+}
+
+// parseStartChallengeEvent parses the challenge from the EventStartChallenge of the submit challenge tx
+func parseStartChallengeEvent(txResp *sdk.TxResponse, txnHash string) (*types.TrackedChallenge, error) {
+	attributes, ok := getEventAttributes(txResp, "EventStartChallenge")
+	if !ok || attributes["challenge_id"] == "" {
+		return nil, types.ErrorChallengeIDNotFound
+	}
+
+	challenge := &types.TrackedChallenge{
+		ObjectId:          attributes["object_id"],
+		SPAddress:         attributes["sp_operator_address"],
+		ChallengerAddress: attributes["challenger_address"],
+		TxnHash:           txnHash,
+		SubmitTime:        time.Now(),
+		Status:            types.ChallengePending,
+	}
+	var err error
+	if challenge.ChallengeId, err = strconv.ParseUint(attributes["challenge_id"], 10, 64); err != nil {
+		return nil, fmt.Errorf("invalid challenge id %s: %s", attributes["challenge_id"], err.Error())
+	}
+	// the zero values are omitted from the event
+	if value := attributes["segment_index"]; value != "" {
+		segmentIndex, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid segment index %s: %s", value, err.Error())
+		}
+		challenge.SegmentIndex = uint32(segmentIndex)
+	}
+	if value := attributes["redundancy_index"]; value != "" {
+		redundancyIndex, err := strconv.ParseInt(value, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redundancy index %s: %s", value, err.Error())
+		}
+		challenge.RedundancyIndex = int32(redundancyIndex)
+	}
+	if value := attributes["expired_height"]; value != "" {
+		if challenge.ExpiredHeight, err = strconv.ParseUint(value, 10, 64); err != nil {
+			return nil, fmt.Errorf("invalid expired height %s: %s", value, err.Error())
+		}
+	}
+	return challenge, nil
+}
+
+// sortChallenges sorts the challenges by the challenge ID
+func sortChallenges(challenges []types.TrackedChallenge) {
+	sort.Slice(challenges, func(i, j int) bool {
+		return challenges[i].ChallengeId < challenges[j].ChallengeId
+	})
+}
@@ -0,0 +1,257 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"cosmossdk.io/math"
+	gnfdsdk "github.com/bnb-chain/greenfield/sdk/types"
+	paymentTypes "github.com/bnb-chain/greenfield/x/payment/types"
+	"github.com/cosmos/cosmos-sdk/types/tx"
+	"github.com/rs/zerolog/log"
+
+	"github.com/bnb-chain/greenfield-go-sdk/types"
+)
+
+// PaymentKeeper watches the payment accounts and deposits from the default account of the client
+// whenever the runway of an account drops below the min runway, the frozen accounts are resumed by the top-up
+type PaymentKeeper struct {
+	client *client
+	opts   types.PaymentKeeperOptions
+
+	mu       sync.Mutex
+	deposits []*deposit
+	metrics  types.PaymentKeeperMetrics
+}
+
+// deposit records the amount deposited at the time, it is used to enforce the daily spending cap.
+// The deposit is reserved before broadcasting and removed if the broadcast fails.
+type deposit struct {
+	time   time.Time
+	amount math.Int
+}
+
+// NewPaymentKeeper returns the payment keeper of the payment accounts, it should be started by Run
+func (c *client) NewPaymentKeeper(opts types.PaymentKeeperOptions) (*PaymentKeeper, error) {
+	if len(opts.Accounts) == 0 {
+		return nil, errors.New("no payment account to watch")
+	}
+	if opts.MinRunway <= 0 || opts.TargetRunway <= opts.MinRunway {
+		return nil, errors.New("the target runway should be longer than the positive min runway")
+	}
+	if opts.MaxDepositPerDay != nil && !opts.MaxDepositPerDay.IsPositive() {
+		return nil, errors.New("the max deposit per day should be positive")
+	}
+	if opts.CheckInterval <= 0 {
+		opts.CheckInterval = types.DefaultPaymentCheckInterval
+	}
+	// the deposit should be committed before the next check
+	if opts.TxOpts == nil {
+		broadcastMode := tx.BroadcastMode_BROADCAST_MODE_BLOCK
+		opts.TxOpts = &gnfdsdk.TxOption{Mode: &broadcastMode}
+	}
+
+	return &PaymentKeeper{
+		client: c,
+		opts:   opts,
+		metrics: types.PaymentKeeperMetrics{
+			TotalDeposited:   math.ZeroInt(),
+			DepositedLastDay: math.ZeroInt(),
+		},
+	}, nil
+}
+
+// Run checks the payment accounts every check interval until the context is done
+func (k *PaymentKeeper) Run(ctx context.Context) error {
+	ticker := time.NewTicker(k.opts.CheckInterval)
+	defer ticker.Stop()
+	for {
+		k.CheckOnce(ctx)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// CheckOnce checks all the payment accounts and tops up the ones whose runway is below the min runway or frozen,
+// it returns the top-up events of this round
+func (k *PaymentKeeper) CheckOnce(ctx context.Context) []types.TopUpEvent {
+	events := make([]types.TopUpEvent, 0)
+	for _, account := range k.opts.Accounts {
+		event, needed, err := k.checkAccount(ctx, account)
+		if err != nil {
+			log.Error().Msg(fmt.Sprintf("fail to check payment account %s: %s", account, err.Error()))
+			continue
+		}
+		if !needed {
+			continue
+		}
+		k.topUp(ctx, &event)
+		events = append(events, event)
+		if k.opts.OnTopUp != nil {
+			k.opts.OnTopUp(event)
+		}
+	}
+
+	k.mu.Lock()
+	k.metrics.Checks++
+	k.metrics.LastCheckTime = time.Now()
+	k.mu.Unlock()
+	return events
+}
+
+// Metrics returns the statistics of the payment keeper
+func (k *PaymentKeeper) Metrics() types.PaymentKeeperMetrics {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	metrics := k.metrics
+	metrics.DepositedLastDay = k.depositedLastDay(time.Now())
+	return metrics
+}
+
+// checkAccount computes the runway of the account and the amount to reach the target runway,
+// it returns false if the account does not need to be topped up
+func (k *PaymentKeeper) checkAccount(ctx context.Context, account string) (types.TopUpEvent, bool, error) {
+	health, err := k.client.getPaymentHealth(ctx, account)
+	if err != nil {
+		return types.TopUpEvent{}, false, err
+	}
+	if health.Status == paymentTypes.STREAM_ACCOUNT_STATUS_FROZEN {
+		return k.checkFrozenAccount(ctx, health)
+	}
+	if health.SettleTime == nil {
+		return types.TopUpEvent{}, false, nil
+	}
+
+	runway := health.SettleTime.Sub(health.BlockTime)
+	if runway >= k.opts.MinRunway {
+		return types.TopUpEvent{}, false, nil
+	}
+
+	// the settle time is postponed by the deposit divided by the burn rate
+	required := health.NetflowRate.Abs().MulRaw(int64((k.opts.TargetRunway - runway) / time.Second))
+	return types.TopUpEvent{
+		Account:  account,
+		Time:     time.Now(),
+		Runway:   runway,
+		Required: required,
+		Amount:   required,
+	}, true, nil
+}
+
+// checkFrozenAccount computes the amount to resume the frozen account with the target runway. The deposit resumes
+// the account only if the static balance covers the reserve of the out flows, the rest of the static balance
+// decides the settle time after resuming.
+func (k *PaymentKeeper) checkFrozenAccount(ctx context.Context, health *types.PaymentHealth) (types.TopUpEvent, bool, error) {
+	// the net flow rate of the frozen account is zero, the out flows are resumed by the deposit
+	totalRate := math.ZeroInt()
+	for _, flow := range health.OutFlows {
+		totalRate = totalRate.Add(flow.Rate)
+	}
+	if !totalRate.IsPositive() {
+		return types.TopUpEvent{}, false, nil
+	}
+	paymentParams, err := k.client.chainClient.PaymentQueryClient.Params(ctx, &paymentTypes.QueryParamsRequest{})
+	if err != nil {
+		return types.TopUpEvent{}, false, err
+	}
+
+	// the static balance is negative if the account owes more than the buffer balance when frozen
+	deficit := math.MaxInt(health.StaticBalance.Neg(), math.ZeroInt())
+	reserve := totalRate.Mul(math.NewIntFromUint64(paymentParams.Params.ReserveTime))
+	minimum := reserve.Add(deficit)
+	// the account is force settled again when the static balance after the reserve lasts less than the forced settle time
+	runwaySeconds := int64(k.opts.TargetRunway/time.Second) + int64(paymentParams.Params.ForcedSettleTime)
+	required := minimum.Add(totalRate.MulRaw(runwaySeconds))
+	return types.TopUpEvent{
+		Account:  health.Account,
+		Time:     time.Now(),
+		Frozen:   true,
+		Minimum:  minimum,
+		Required: required,
+		Amount:   required,
+	}, true, nil
+}
+
+// topUp deposits the amount of the event within the daily spending cap and updates the metrics.
+// The amount is reserved in the deposits before broadcasting, so the concurrent checks can not exceed the cap together.
+func (k *PaymentKeeper) topUp(ctx context.Context, event *types.TopUpEvent) {
+	reserved, err := k.reserve(event)
+	if err != nil {
+		event.Err = err
+		log.Error().Msg(fmt.Sprintf("skip topping up payment account %s: %s", event.Account, event.Err.Error()))
+		return
+	}
+
+	event.TxnHash, event.Err = k.client.Deposit(ctx, event.Account, event.Amount, *k.opts.TxOpts)
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if event.Err != nil {
+		k.release(reserved)
+		k.metrics.FailedTopUps++
+		log.Error().Msg(fmt.Sprintf("fail to top up payment account %s: %s", event.Account, event.Err.Error()))
+		return
+	}
+	k.metrics.TopUps++
+	k.metrics.TotalDeposited = k.metrics.TotalDeposited.Add(event.Amount)
+	log.Info().Msg(fmt.Sprintf("top up payment account %s with %s BNB, txn hash: %s", event.Account,
+		types.WeiToBNB(event.Amount), event.TxnHash))
+}
+
+// reserve caps the amount of the event by the max deposit per day and records it as a deposit,
+// nothing is recorded if the capped amount can not top up the account
+func (k *PaymentKeeper) reserve(event *types.TopUpEvent) (*deposit, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.opts.MaxDepositPerDay != nil {
+		remaining := k.opts.MaxDepositPerDay.Sub(k.depositedLastDay(event.Time))
+		if event.Amount.GT(remaining) {
+			event.Capped = true
+			event.Amount = math.MaxInt(remaining, math.ZeroInt())
+			k.metrics.CappedTopUps++
+		}
+	}
+
+	if !event.Amount.IsPositive() {
+		return nil, errors.New("the max deposit per day is reached")
+	}
+	// the deposit less than the minimum does not resume the frozen account
+	if event.Frozen && event.Amount.LT(event.Minimum) {
+		return nil, fmt.Errorf("the amount %s within the max deposit per day can not resume the frozen account, at least %s is required",
+			event.Amount.String(), event.Minimum.String())
+	}
+	reserved := &deposit{time: event.Time, amount: event.Amount}
+	k.deposits = append(k.deposits, reserved)
+	return reserved, nil
+}
+
+// release removes the reserved deposit whose broadcast fails, it should be called with the lock held
+func (k *PaymentKeeper) release(reserved *deposit) {
+	for i, d := range k.deposits {
+		if d == reserved {
+			k.deposits = append(k.deposits[:i], k.deposits[i+1:]...)
+			return
+		}
+	}
+}
+
+// depositedLastDay returns the amount deposited in the 24 hours before now and drops the older deposits,
+// it should be called with the lock held
+func (k *PaymentKeeper) depositedLastDay(now time.Time) math.Int {
+	total := math.ZeroInt()
+	kept := k.deposits[:0]
+	for _, d := range k.deposits {
+		if now.Sub(d.time) < 24*time.Hour {
+			kept = append(kept, d)
+			total = total.Add(d.amount)
+		}
+	}
+	k.deposits = kept
+	return total
+}
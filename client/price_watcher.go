@@ -0,0 +1,138 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	spTypes "github.com/bnb-chain/greenfield/x/sp/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/rs/zerolog/log"
+
+	"github.com/bnb-chain/greenfield-go-sdk/types"
+)
+
+// StoragePriceWatcher queries the prices of the SPs periodically and notifies the read or store price changes
+type StoragePriceWatcher struct {
+	client *client
+	opts   types.StoragePriceWatchOptions
+
+	mu sync.Mutex
+	// prices indicates the last observed price of each SP
+	prices map[string]*spTypes.SpStoragePrice
+}
+
+// NewStoragePriceWatcher returns the price watcher of the SPs, it should be started by Run
+func (c *client) NewStoragePriceWatcher(opts types.StoragePriceWatchOptions) (*StoragePriceWatcher, error) {
+	if len(opts.SPs) == 0 {
+		return nil, errors.New("no SP to watch")
+	}
+	for _, spAddr := range opts.SPs {
+		if _, err := sdk.AccAddressFromHexUnsafe(spAddr); err != nil {
+			return nil, fmt.Errorf("invalid SP address %s: %s", spAddr, err.Error())
+		}
+	}
+	if opts.CheckInterval <= 0 {
+		opts.CheckInterval = types.DefaultPriceCheckInterval
+	}
+
+	return &StoragePriceWatcher{
+		client: c,
+		opts:   opts,
+		prices: make(map[string]*spTypes.SpStoragePrice),
+	}, nil
+}
+
+// Run checks the prices every check interval until the context is done
+func (w *StoragePriceWatcher) Run(ctx context.Context) error {
+	ticker := time.NewTicker(w.opts.CheckInterval)
+	defer ticker.Stop()
+	for {
+		w.CheckOnce(ctx)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// CheckOnce queries the current prices of the SPs and returns the changes since the last check,
+// the first check of an SP records the current price without notification.
+// Every update between two checks is reported in the order of the update time.
+func (w *StoragePriceWatcher) CheckOnce(ctx context.Context) []types.StoragePriceChange {
+	w.mu.Lock()
+	changes := make([]types.StoragePriceChange, 0)
+	for _, spAddr := range w.opts.SPs {
+		spChanges, err := w.checkSP(ctx, spAddr)
+		if err != nil {
+			log.Error().Msg(fmt.Sprintf("fail to check the storage price of SP %s: %s", spAddr, err.Error()))
+			continue
+		}
+		changes = append(changes, spChanges...)
+	}
+	w.mu.Unlock()
+
+	if w.opts.OnChange != nil {
+		for _, change := range changes {
+			w.opts.OnChange(change)
+		}
+	}
+	return changes
+}
+
+// Prices returns the last observed prices of the SPs
+func (w *StoragePriceWatcher) Prices() map[string]*spTypes.SpStoragePrice {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	prices := make(map[string]*spTypes.SpStoragePrice, len(w.prices))
+	for spAddr, price := range w.prices {
+		prices[spAddr] = price
+	}
+	return prices
+}
+
+// checkSP walks the price updates of the SP since the last observed price, it should be called with the lock held
+func (w *StoragePriceWatcher) checkSP(ctx context.Context, spAddr string) ([]types.StoragePriceChange, error) {
+	current, err := w.client.GetStoragePrice(ctx, spAddr)
+	if err != nil {
+		return nil, err
+	}
+	last, ok := w.prices[spAddr]
+	w.prices[spAddr] = current
+	if !ok || current.UpdateTimeSec == last.UpdateTimeSec {
+		return nil, nil
+	}
+
+	history, err := w.client.GetStoragePriceHistory(ctx, spAddr, time.Unix(last.UpdateTimeSec, 0), time.Unix(current.UpdateTimeSec, 0))
+	if err != nil {
+		// report the change between the two observed prices if the updates can not be walked
+		log.Error().Msg(fmt.Sprintf("fail to get the price history of SP %s: %s", spAddr, err.Error()))
+		history = []*spTypes.SpStoragePrice{last, current}
+	}
+
+	now := time.Now()
+	changes := make([]types.StoragePriceChange, 0)
+	prev := last
+	for _, price := range history {
+		if price.UpdateTimeSec <= prev.UpdateTimeSec {
+			continue
+		}
+		change := types.StoragePriceChange{
+			SPAddress:         spAddr,
+			Old:               prev,
+			New:               price,
+			ReadPriceChanged:  !price.ReadPrice.Equal(prev.ReadPrice),
+			StorePriceChanged: !price.StorePrice.Equal(prev.StorePrice),
+			DetectTime:        now,
+		}
+		prev = price
+		// the free read quota may be updated without the price change
+		if change.ReadPriceChanged || change.StorePriceChanged {
+			changes = append(changes, change)
+		}
+	}
+	return changes, nil
+}
@@ -0,0 +1,228 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	spTypes "github.com/bnb-chain/greenfield/x/sp/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/rs/zerolog/log"
+
+	"github.com/bnb-chain/greenfield-go-sdk/types"
+)
+
+// SPHealthChecker probes the endpoints of all the SPs on chain and tracks their health,
+// the requests routed to the primary SP of a bucket fail fast if the SP is known to be unhealthy
+type SPHealthChecker struct {
+	client *client
+	opts   types.SPHealthCheckOptions
+
+	mu      sync.RWMutex
+	records map[string]*spHealthRecord
+}
+
+// spHealthRecord keeps the results of the recent probes of the SP
+type spHealthRecord struct {
+	health types.SPHealth
+	// latencies and failures are the results of the probes in the window, the latency of the failed probe is zero
+	latencies []time.Duration
+	failures  []bool
+}
+
+// NewSPHealthChecker returns the SP health checker, it should be started by Run or refreshed by CheckOnce,
+// and installed by SetSPHealthChecker to route the requests of the client
+func (c *client) NewSPHealthChecker(opts types.SPHealthCheckOptions) *SPHealthChecker {
+	if opts.CheckInterval <= 0 {
+		opts.CheckInterval = types.DefaultSPHealthCheckInterval
+	}
+	if opts.ProbeTimeout <= 0 {
+		opts.ProbeTimeout = types.DefaultSPProbeTimeout
+	}
+	if opts.WindowSize <= 0 {
+		opts.WindowSize = types.DefaultSPHealthWindowSize
+	}
+	if opts.FailureThreshold <= 0 {
+		opts.FailureThreshold = types.DefaultSPFailureThreshold
+	}
+
+	return &SPHealthChecker{
+		client:  c,
+		opts:    opts,
+		records: make(map[string]*spHealthRecord),
+	}
+}
+
+// SetSPHealthChecker installs the SP health checker used by the requests of the client, it replaces the installed one
+func (c *client) SetSPHealthChecker(checker *SPHealthChecker) {
+	c.spHealthMtx.Lock()
+	defer c.spHealthMtx.Unlock()
+	c.spHealthChecker = checker
+}
+
+// Run probes the SPs every check interval until the context is done
+func (h *SPHealthChecker) Run(ctx context.Context) error {
+	ticker := time.NewTicker(h.opts.CheckInterval)
+	defer ticker.Stop()
+	for {
+		if err := h.CheckOnce(ctx); err != nil {
+			log.Error().Msg(fmt.Sprintf("fail to check the health of SPs: %s", err.Error()))
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// CheckOnce queries the SPs on chain and probes their endpoints concurrently,
+// the SPs which are removed from chain are no longer tracked
+func (h *SPHealthChecker) CheckOnce(ctx context.Context) error {
+	spList, err := h.client.ListStorageProviders(ctx, false)
+	if err != nil {
+		return err
+	}
+
+	type probeResult struct {
+		sp      spTypes.StorageProvider
+		latency time.Duration
+		err     error
+	}
+	results := make([]probeResult, len(spList))
+	var wg sync.WaitGroup
+	for i, sp := range spList {
+		wg.Add(1)
+		go func(i int, sp spTypes.StorageProvider) {
+			defer wg.Done()
+			latency, err := probeEndpoint(ctx, h.client.httpClient, sp.Endpoint, h.opts.ProbeTimeout)
+			results[i] = probeResult{sp: sp, latency: latency, err: err}
+		}(i, sp)
+	}
+	wg.Wait()
+
+	now := time.Now()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	records := make(map[string]*spHealthRecord, len(results))
+	for _, result := range results {
+		operator := result.sp.GetOperator().String()
+		record, ok := h.records[operator]
+		if !ok {
+			record = &spHealthRecord{}
+		}
+		h.update(record, result.sp, now, result.latency, result.err)
+		records[operator] = record
+	}
+	h.records = records
+	return nil
+}
+
+// GetSPHealth returns the health of the SP, it returns false if the SP has not been probed.
+// spAddr indicates the HEX-encoded string of the SP operator address
+func (h *SPHealthChecker) GetSPHealth(spAddr string) (types.SPHealth, bool) {
+	spAcc, err := sdk.AccAddressFromHexUnsafe(spAddr)
+	if err != nil {
+		return types.SPHealth{}, false
+	}
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	record, ok := h.records[spAcc.String()]
+	if !ok {
+		return types.SPHealth{}, false
+	}
+	return record.health, true
+}
+
+// ListSPHealth returns the health of all the probed SPs ordered by the operator address
+func (h *SPHealthChecker) ListSPHealth() []types.SPHealth {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	healthList := make([]types.SPHealth, 0, len(h.records))
+	for _, record := range h.records {
+		healthList = append(healthList, record.health)
+	}
+	sort.Slice(healthList, func(i, j int) bool {
+		return healthList[i].OperatorAddress < healthList[j].OperatorAddress
+	})
+	return healthList
+}
+
+// update appends the probe result to the window of the record and recomputes the health,
+// it should be called with the lock held
+func (h *SPHealthChecker) update(record *spHealthRecord, sp spTypes.StorageProvider, now time.Time,
+	latency time.Duration, probeErr error,
+) {
+	record.latencies = append(record.latencies, latency)
+	record.failures = append(record.failures, probeErr != nil)
+	if len(record.failures) > h.opts.WindowSize {
+		record.latencies = record.latencies[len(record.latencies)-h.opts.WindowSize:]
+		record.failures = record.failures[len(record.failures)-h.opts.WindowSize:]
+	}
+
+	health := &record.health
+	health.OperatorAddress = sp.GetOperator().String()
+	health.Endpoint = sp.Endpoint
+	health.Status = sp.Status
+	health.LastCheckTime = now
+	if probeErr != nil {
+		health.LastError = probeErr.Error()
+		health.ConsecutiveFailures++
+	} else {
+		health.LastError = ""
+		health.ConsecutiveFailures = 0
+	}
+
+	succeeded := make([]time.Duration, 0, len(record.latencies))
+	health.Probes = len(record.failures)
+	health.Failures = 0
+	for i, failed := range record.failures {
+		if failed {
+			health.Failures++
+			continue
+		}
+		succeeded = append(succeeded, record.latencies[i])
+	}
+	health.Availability = float64(health.Probes-health.Failures) / float64(health.Probes)
+	sort.Slice(succeeded, func(i, j int) bool { return succeeded[i] < succeeded[j] })
+	health.LatencyP50 = percentile(succeeded, 50)
+	health.LatencyP90 = percentile(succeeded, 90)
+	health.LatencyP99 = percentile(succeeded, 99)
+
+	// the graceful exiting SP still serves the existing buckets
+	serving := sp.Status == spTypes.STATUS_IN_SERVICE || sp.Status == spTypes.STATUS_GRACEFUL_EXITING
+	health.Healthy = serving && health.ConsecutiveFailures < h.opts.FailureThreshold
+}
+
+// percentile returns the nearest-rank percentile of the sorted latencies, it returns zero if there is no latency
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// checkSPHealth returns an error if the SP is known to be unhealthy by the SP health checker of the client
+func (c *client) checkSPHealth(spAddr string) error {
+	c.spHealthMtx.RLock()
+	checker := c.spHealthChecker
+	c.spHealthMtx.RUnlock()
+	if checker == nil {
+		return nil
+	}
+	health, ok := checker.GetSPHealth(spAddr)
+	if !ok || health.Healthy {
+		return nil
+	}
+	reason := health.Status.String()
+	if health.LastError != "" {
+		reason = fmt.Sprintf("%s, %d consecutive failed probes, last error: %s", reason, health.ConsecutiveFailures, health.LastError)
+	}
+	return fmt.Errorf("%w %s: %s", types.ErrorSPUnhealthy, spAddr, reason)
+}
@@ -0,0 +1,275 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	spTypes "github.com/bnb-chain/greenfield/x/sp/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/rs/zerolog/log"
+
+	"github.com/bnb-chain/greenfield-go-sdk/pkg/utils"
+	"github.com/bnb-chain/greenfield-go-sdk/types"
+)
+
+// SPSelector decides which storage providers are used as the primary SP of the new bucket
+// and the secondary SPs of the new object
+type SPSelector interface {
+	// Select returns the candidates in the order of preference, the SPs which should not be used are dropped
+	Select(ctx context.Context, candidates []spTypes.StorageProvider) ([]spTypes.StorageProvider, error)
+}
+
+// priceSelector prefers the SPs with the lower store price
+type priceSelector struct {
+	sp SP
+}
+
+// NewPriceSelector returns the selector which orders the SPs by the store price and then the read price,
+// the SPs whose price can not be queried are dropped
+func NewPriceSelector(sp SP) SPSelector {
+	return &priceSelector{sp: sp}
+}
+
+func (s *priceSelector) Select(ctx context.Context, candidates []spTypes.StorageProvider) ([]spTypes.StorageProvider, error) {
+	prices := make(map[string]*spTypes.SpStoragePrice, len(candidates))
+	selected := make([]spTypes.StorageProvider, 0, len(candidates))
+	for _, sp := range candidates {
+		price, err := s.sp.GetStoragePrice(ctx, sp.OperatorAddress)
+		if err != nil {
+			log.Error().Msg(fmt.Sprintf("fail to get the storage price of SP %s: %s", sp.OperatorAddress, err.Error()))
+			continue
+		}
+		prices[sp.OperatorAddress] = price
+		selected = append(selected, sp)
+	}
+
+	sort.SliceStable(selected, func(i, j int) bool {
+		pi, pj := prices[selected[i].OperatorAddress], prices[selected[j].OperatorAddress]
+		if !pi.StorePrice.Equal(pj.StorePrice) {
+			return pi.StorePrice.LT(pj.StorePrice)
+		}
+		return pi.ReadPrice.LT(pj.ReadPrice)
+	})
+	return selected, nil
+}
+
+// latencySelector prefers the SPs whose endpoints respond faster
+type latencySelector struct {
+	httpClient *http.Client
+	timeout    time.Duration
+
+	mu        sync.Mutex
+	latencies map[string]time.Duration
+	probeTime time.Time
+}
+
+// NewLatencySelector returns the selector which probes the endpoints of the SPs concurrently and orders them
+// by the response time, the unreachable SPs are dropped. The probe results are reused for SPLatencyCacheTTL.
+// DefaultSPProbeTimeout is used if the timeout is not positive.
+func NewLatencySelector(httpClient *http.Client, timeout time.Duration) SPSelector {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	if timeout <= 0 {
+		timeout = types.DefaultSPProbeTimeout
+	}
+	return &latencySelector{httpClient: httpClient, timeout: timeout}
+}
+
+func (s *latencySelector) Select(ctx context.Context, candidates []spTypes.StorageProvider) ([]spTypes.StorageProvider, error) {
+	latencies := s.probeAll(ctx, candidates)
+
+	selected := make([]spTypes.StorageProvider, 0, len(candidates))
+	for _, sp := range candidates {
+		if _, ok := latencies[sp.OperatorAddress]; ok {
+			selected = append(selected, sp)
+		}
+	}
+	sort.SliceStable(selected, func(i, j int) bool {
+		return latencies[selected[i].OperatorAddress] < latencies[selected[j].OperatorAddress]
+	})
+	return selected, nil
+}
+
+// probeAll returns the latencies of the reachable candidates, the cached latencies are returned
+// if all the candidates were probed within the cache TTL
+func (s *latencySelector) probeAll(ctx context.Context, candidates []spTypes.StorageProvider) map[string]time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.latencies != nil && time.Since(s.probeTime) < types.SPLatencyCacheTTL {
+		cached := true
+		for _, sp := range candidates {
+			if _, ok := s.latencies[sp.OperatorAddress]; !ok {
+				cached = false
+				break
+			}
+		}
+		if cached {
+			return s.latencies
+		}
+	}
+
+	var wg sync.WaitGroup
+	var resultMu sync.Mutex
+	latencies := make(map[string]time.Duration, len(candidates))
+	for _, sp := range candidates {
+		wg.Add(1)
+		go func(sp spTypes.StorageProvider) {
+			defer wg.Done()
+			latency, err := probeEndpoint(ctx, s.httpClient, sp.Endpoint, s.timeout)
+			if err != nil {
+				log.Error().Msg(fmt.Sprintf("fail to probe SP %s: %s", sp.OperatorAddress, err.Error()))
+				return
+			}
+			resultMu.Lock()
+			latencies[sp.OperatorAddress] = latency
+			resultMu.Unlock()
+		}(sp)
+	}
+	wg.Wait()
+
+	s.latencies = latencies
+	s.probeTime = time.Now()
+	return latencies
+}
+
+// probeEndpoint sends a request to the SP endpoint and returns the time to receive the response,
+// any http response indicates the endpoint is reachable
+func probeEndpoint(ctx context.Context, httpClient *http.Client, endpoint string, timeout time.Duration) (time.Duration, error) {
+	endpointURL, err := utils.GetEndpointURL(endpoint, strings.Contains(endpoint, "https"))
+	if err != nil {
+		return 0, err
+	}
+
+	probeCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(probeCtx, http.MethodHead, endpointURL.String(), nil)
+	if err != nil {
+		return 0, err
+	}
+
+	start := time.Now()
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	latency := time.Since(start)
+	utils.CloseResponse(resp)
+	return latency, nil
+}
+
+// roundRobinSelector rotates the SPs on each selection to spread the buckets and objects
+type roundRobinSelector struct {
+	next uint64
+}
+
+// NewRoundRobinSelector returns the selector which starts from the next SP on each selection,
+// the SPs are ordered by the operator address
+func NewRoundRobinSelector() SPSelector {
+	return &roundRobinSelector{}
+}
+
+func (s *roundRobinSelector) Select(_ context.Context, candidates []spTypes.StorageProvider) ([]spTypes.StorageProvider, error) {
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+	sorted := make([]spTypes.StorageProvider, len(candidates))
+	copy(sorted, candidates)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].OperatorAddress < sorted[j].OperatorAddress
+	})
+
+	start := int((atomic.AddUint64(&s.next, 1) - 1) % uint64(len(sorted)))
+	return append(sorted[start:], sorted[:start]...), nil
+}
+
+// filterSelector drops the SPs which are not allowed before the next selector
+type filterSelector struct {
+	allow map[string]bool
+	deny  map[string]bool
+	next  SPSelector
+}
+
+// NewFilterSelector returns the selector which only keeps the SPs in the allow list and not in the deny list,
+// all the SPs are allowed if the allow list is empty. The remaining SPs are ordered by the next selector if it is not nil.
+// The lists contain the HEX-encoded operator addresses of the SPs.
+func NewFilterSelector(allow, deny []string, next SPSelector) (SPSelector, error) {
+	s := &filterSelector{allow: make(map[string]bool), deny: make(map[string]bool), next: next}
+	for _, addr := range allow {
+		spAcc, err := sdk.AccAddressFromHexUnsafe(addr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SP address %s in the allow list: %s", addr, err.Error())
+		}
+		s.allow[spAcc.String()] = true
+	}
+	for _, addr := range deny {
+		spAcc, err := sdk.AccAddressFromHexUnsafe(addr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SP address %s in the deny list: %s", addr, err.Error())
+		}
+		s.deny[spAcc.String()] = true
+	}
+	return s, nil
+}
+
+func (s *filterSelector) Select(ctx context.Context, candidates []spTypes.StorageProvider) ([]spTypes.StorageProvider, error) {
+	selected := make([]spTypes.StorageProvider, 0, len(candidates))
+	for _, sp := range candidates {
+		operator := sp.GetOperator().String()
+		if s.deny[operator] || (len(s.allow) > 0 && !s.allow[operator]) {
+			continue
+		}
+		selected = append(selected, sp)
+	}
+	if s.next == nil {
+		return selected, nil
+	}
+	return s.next.Select(ctx, selected)
+}
+
+// selectSPs returns at most n in-service SPs chosen by the selector of the client, the SPs in the exclude list
+// and the unhealthy SPs are not chosen.
+// The SPs are returned in the order on chain if the client has no selector.
+func (c *client) selectSPs(ctx context.Context, n int, exclude ...string) ([]spTypes.StorageProvider, error) {
+	spList, err := c.ListStorageProviders(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]spTypes.StorageProvider, 0, len(spList))
+	for _, sp := range spList {
+		excluded := false
+		for _, addr := range exclude {
+			if strings.EqualFold(sp.OperatorAddress, addr) {
+				excluded = true
+				break
+			}
+		}
+		// the SPs known to be unhealthy are not chosen
+		if !excluded && c.checkSPHealth(sp.OperatorAddress) == nil {
+			candidates = append(candidates, sp)
+		}
+	}
+
+	if c.spSelector != nil {
+		candidates, err = c.spSelector.Select(ctx, candidates)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, errors.New("no in-service SP is selected")
+	}
+	if n > 0 && len(candidates) > n {
+		candidates = candidates[:n]
+	}
+	return candidates, nil
+}
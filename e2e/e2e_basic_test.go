@@ -70,9 +70,9 @@ func (s *BasicTestSuite) Test_Account() {
 	s.Require().Equal(acc.GetAddress(), account1.GetAddress())
 	s.Require().Equal(acc.GetSequence(), uint64(0))
 
-	txHash, err := s.Client.CreatePaymentAccount(s.ClientContext, s.DefaultAccount.GetAddress().String(), types2.TxOption{})
+	paymentAddr, txHash, err := s.Client.CreatePaymentAccount(s.ClientContext, s.DefaultAccount.GetAddress().String(), types2.TxOption{})
 	s.Require().NoError(err)
-	s.T().Logf("Acc: %s", txHash)
+	s.T().Logf("Acc: %s, tx: %s", paymentAddr, txHash)
 	waitForTx, err = s.Client.WaitForTx(s.ClientContext, txHash)
 	s.Require().NoError(err)
 	s.T().Logf("Wair for tx: %s", waitForTx.String())
@@ -80,6 +80,7 @@ func (s *BasicTestSuite) Test_Account() {
 	paymentAccountsByOwner, err := s.Client.GetPaymentAccountsByOwner(s.ClientContext, s.DefaultAccount.GetAddress().String())
 	s.Require().NoError(err)
 	s.Require().Equal(len(paymentAccountsByOwner), 1)
+	s.Require().Equal(paymentAccountsByOwner[0].Addr, paymentAddr)
 }
 
 func (s *BasicTestSuite) Test_MultiTransfer() {
@@ -143,9 +144,9 @@ func (s *BasicTestSuite) Test_Payment() {
 
 	paymentAccountsBeforeCreate, err := cli.GetPaymentAccountsByOwner(ctx, account.GetAddress().String())
 	s.Require().NoError(err)
-	txHash, err := cli.CreatePaymentAccount(ctx, account.GetAddress().String(), types2.TxOption{})
+	createdAddr, txHash, err := cli.CreatePaymentAccount(ctx, account.GetAddress().String(), types2.TxOption{})
 	s.Require().NoError(err)
-	t.Logf("Acc: %s", txHash)
+	t.Logf("Acc: %s, tx: %s", createdAddr, txHash)
 	waitForTx, err := cli.WaitForTx(ctx, txHash)
 	s.Require().NoError(err)
 	t.Logf("Wair for tx: %s", waitForTx.String())
@@ -156,6 +157,7 @@ func (s *BasicTestSuite) Test_Payment() {
 
 	// deposit
 	paymentAddr := paymentAccountsByOwnerAfterCreate[len(paymentAccountsByOwnerAfterCreate)-1].Addr
+	s.Require().Equal(paymentAddr, createdAddr)
 	depositAmount := math.NewIntFromUint64(100)
 	depositTxHash, err := cli.Deposit(ctx, paymentAddr, depositAmount, types2.TxOption{})
 	s.Require().NoError(err)
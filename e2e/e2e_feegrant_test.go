@@ -67,7 +67,7 @@ func (s *FeeGrantTestSuite) Test_FeeGrant() {
 
 	// grantee makes a tx and costs the fee provided by granter
 	cli.SetDefaultAccount(grantee)
-	txHash, err = cli.CreatePaymentAccount(ctx, granteeAddr, types2.TxOption{
+	_, txHash, err = cli.CreatePaymentAccount(ctx, granteeAddr, types2.TxOption{
 		FeeGranter: granter.GetAddress(),
 	})
 
@@ -92,7 +92,7 @@ func (s *FeeGrantTestSuite) Test_FeeGrant() {
 
 	// transaction is failed
 	cli.SetDefaultAccount(grantee)
-	_, err = cli.CreatePaymentAccount(ctx, granteeAddr, types2.TxOption{
+	_, _, err = cli.CreatePaymentAccount(ctx, granteeAddr, types2.TxOption{
 		FeeGranter: granter.GetAddress(),
 	})
 	s.Require().Error(err)
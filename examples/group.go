@@ -57,6 +57,22 @@ func main() {
 		log.Fatalf("head group member %s fail \n", groupMember)
 	}
 
+	// list groups of the owner and members of the group
+	groups, err := cli.ListGroups(ctx, creator.GetAddress().String(), types.ListGroupsOptions{Limit: 10})
+	handleErr(err, "ListGroups")
+	log.Printf("the owner has %d groups \n", len(groups.Groups))
+
+	members, err := cli.ListGroupMembers(ctx, groupName, creator.GetAddress().String(), types.ListGroupMembersOptions{Limit: 10})
+	handleErr(err, "ListGroupMembers")
+	for _, member := range members.Members {
+		log.Println("group member:", member.AccountID)
+	}
+
+	// plan the member changes to sync the group members with the roster
+	report, err := cli.SyncGroupMembers(ctx, groupName, []string{groupMember}, types.SyncGroupMembersOptions{DryRun: true})
+	handleErr(err, "SyncGroupMembers")
+	log.Println(report.String())
+
 	// delete group
 	delTx, err := cli.DeleteGroup(ctx, groupName, types.DeleteGroupOption{})
 	handleErr(err, "DeleteGroup")
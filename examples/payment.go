@@ -22,19 +22,15 @@ func main() {
 	}
 	ctx := context.Background()
 	// create a payment account
-	txHash, err := cli.CreatePaymentAccount(context.Background(), account.GetAddress().String(), gnfdsdktypes.TxOption{})
+	paymentAddr, txHash, err := cli.CreatePaymentAccount(context.Background(), account.GetAddress().String(), gnfdsdktypes.TxOption{})
 	handleErr(err, "CreatePaymentAccount")
-	waitForTx, err := cli.WaitForTx(ctx, txHash)
-	log.Printf("Wait for tx: %s", waitForTx.String())
-
-	paymentAccounts, err := cli.GetPaymentAccountsByOwner(ctx, account.GetAddress().String())
+	log.Printf("created payment account %s, txHash=%s", paymentAddr, txHash)
 
 	// deposit
-	paymentAddr := paymentAccounts[len(paymentAccounts)-1].Addr
 	depositAmount := math.NewIntFromUint64(100)
 	depositTxHash, err := cli.Deposit(ctx, paymentAddr, depositAmount, gnfdsdktypes.TxOption{})
 	handleErr(err, "Deposit")
-	waitForTx, err = cli.WaitForTx(ctx, txHash)
+	waitForTx, err := cli.WaitForTx(ctx, depositTxHash)
 	log.Printf("Wait for tx: %s", waitForTx.String())
 	log.Printf("deposited %s to payment account %s, txHash=%s", depositAmount.String(), paymentAddr, depositTxHash)
 
@@ -77,4 +77,31 @@ func main() {
 	if effect != permTypes.EFFECT_ALLOW {
 		log.Fatalln("permission not allowed to:", principal)
 	}
+
+	// apply the policy document built by policy builder
+	policyDoc, err := types.NewPolicyBuilder().
+		Allow(permTypes.ACTION_GET_OBJECT, permTypes.ACTION_LIST_OBJECT).ToAccount(principal).OnBucket(bucketName).
+		Deny(permTypes.ACTION_DELETE_OBJECT).ToAccount(principal).OnObject(bucketName, objectName).
+		Build()
+	handleErr(err, "BuildPolicyDocument")
+
+	policyTxs, err := cli.ApplyPolicyDocument(ctx, policyDoc, types.ApplyPolicyOption{})
+	handleErr(err, "ApplyPolicyDocument")
+	log.Printf("apply policy document successfully, txns: %v\n", policyTxs)
+
+	// plan the permission changes against the policy document and apply them
+	plan, err := cli.PlanPermissions(ctx, types.PermissionState{Document: policyDoc})
+	handleErr(err, "PlanPermissions")
+	log.Println(plan.String())
+
+	if !plan.IsEmpty() {
+		_, err = cli.ApplyPlan(ctx, plan, types.ApplyPlanOption{})
+		handleErr(err, "ApplyPlan")
+	}
+
+	// explain why the permission is allowed or denied
+	explanation, err := cli.ExplainPermission(ctx, principal, bucketName, objectName, permTypes.ACTION_DELETE_OBJECT,
+		types.ExplainPermissionOptions{})
+	handleErr(err, "ExplainPermission")
+	log.Println(explanation.String())
 }
@@ -8,6 +8,7 @@ require (
 	github.com/bnb-chain/greenfield v0.1.2
 	github.com/bnb-chain/greenfield-common/go v0.0.0-20230512031838-33b0f124a4cf
 	github.com/cosmos/cosmos-sdk v0.46.4
+	github.com/klauspost/compress v1.15.11
 	github.com/rs/zerolog v1.29.0
 	github.com/stretchr/testify v1.8.1
 	github.com/tendermint/tendermint v0.34.22
@@ -73,7 +74,6 @@ require (
 	github.com/herumi/bls-eth-go-binary v0.0.0-20210917013441-d37c07cfda4e // indirect
 	github.com/inconshreveable/mousetrap v1.0.1 // indirect
 	github.com/jmhodges/levigo v1.0.0 // indirect
-	github.com/klauspost/compress v1.15.11 // indirect
 	github.com/klauspost/cpuid/v2 v2.2.4 // indirect
 	github.com/klauspost/reedsolomon v1.11.7 // indirect
 	github.com/libp2p/go-buffer-pool v0.1.0 // indirect
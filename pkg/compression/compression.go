@@ -0,0 +1,173 @@
+package compression
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"mime"
+	"strconv"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/bnb-chain/greenfield-go-sdk/types"
+)
+
+const (
+	// CodecGzip is the name of the built-in gzip codec
+	CodecGzip = "gzip"
+	// CodecZstd is the name of the built-in zstd codec
+	CodecZstd = "zstd"
+
+	// ContentTypeParamCodec is the content type parameter which records the codec of the stored payload
+	ContentTypeParamCodec = "codec"
+	// ContentTypeParamLogicalSize is the content type parameter which records the size of the uncompressed payload
+	ContentTypeParamLogicalSize = "logical-size"
+)
+
+// Codec compresses and decompresses the object payload.
+// The compressed output must be deterministic for the same input, since the payload is compressed
+// once to compute the hash roots and once more to upload.
+type Codec interface {
+	Name() string
+	NewWriter(w io.Writer) (io.WriteCloser, error)
+	NewReader(r io.Reader) (io.ReadCloser, error)
+}
+
+var (
+	codecsMtx sync.RWMutex
+	codecs    = map[string]Codec{
+		CodecGzip: gzipCodec{},
+		CodecZstd: zstdCodec{},
+	}
+)
+
+// RegisterCodec registers the codec by its name, the registered codec replaces the built-in codec with the same name
+func RegisterCodec(codec Codec) {
+	codecsMtx.Lock()
+	defer codecsMtx.Unlock()
+	codecs[codec.Name()] = codec
+}
+
+// GetCodec returns the registered codec by the name
+func GetCodec(name string) (Codec, error) {
+	codecsMtx.RLock()
+	defer codecsMtx.RUnlock()
+	codec, ok := codecs[name]
+	if !ok {
+		return nil, fmt.Errorf("compression codec %s is not registered", name)
+	}
+	return codec, nil
+}
+
+// gzipCodec compresses with the default level, the gzip header carries no modification time so the output is deterministic
+type gzipCodec struct{}
+
+func (gzipCodec) Name() string {
+	return CodecGzip
+}
+
+func (gzipCodec) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	return gzip.NewWriterLevel(w, gzip.DefaultCompression)
+}
+
+func (gzipCodec) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return gzip.NewReader(r)
+}
+
+// zstdCodec compresses with the default level in a single goroutine, so the output is deterministic
+type zstdCodec struct{}
+
+func (zstdCodec) Name() string {
+	return CodecZstd
+}
+
+func (zstdCodec) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	return zstd.NewWriter(w, zstd.WithEncoderConcurrency(1))
+}
+
+func (zstdCodec) NewReader(r io.Reader) (io.ReadCloser, error) {
+	decoder, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+	if err != nil {
+		return nil, err
+	}
+	return decoder.IOReadCloser(), nil
+}
+
+// CompressReader reads the compressed payload of the source reader
+type CompressReader struct {
+	pipeReader  *io.PipeReader
+	logicalSize int64
+}
+
+// NewCompressReader returns the reader of the compressed payload, it compresses the source reader in background
+func NewCompressReader(reader io.Reader, codec Codec) *CompressReader {
+	pipeReader, pipeWriter := io.Pipe()
+	compressReader := &CompressReader{pipeReader: pipeReader}
+
+	go func() {
+		writer, err := codec.NewWriter(pipeWriter)
+		if err != nil {
+			pipeWriter.CloseWithError(err)
+			return
+		}
+		n, err := io.Copy(writer, reader)
+		if closeErr := writer.Close(); err == nil {
+			err = closeErr
+		}
+		compressReader.logicalSize = n
+		pipeWriter.CloseWithError(err)
+	}()
+	return compressReader
+}
+
+// Read reads the compressed payload
+func (r *CompressReader) Read(p []byte) (int, error) {
+	return r.pipeReader.Read(p)
+}
+
+// Close stops the compression in background
+func (r *CompressReader) Close() error {
+	return r.pipeReader.Close()
+}
+
+// LogicalSize returns the size of the uncompressed payload, it is valid after the compressed payload is read to EOF
+func (r *CompressReader) LogicalSize() int64 {
+	return r.logicalSize
+}
+
+// EncodeContentType records the codec and the logical size in the parameters of the content type.
+// The malformed parameters are dropped, and the default content type is used if the media type is malformed.
+func EncodeContentType(contentType string, codec string, logicalSize int64) string {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		params = map[string]string{}
+		if mediaType == "" {
+			mediaType = types.ContentDefault
+		}
+	}
+	params[ContentTypeParamCodec] = codec
+	params[ContentTypeParamLogicalSize] = strconv.FormatInt(logicalSize, 10)
+	return mime.FormatMediaType(mediaType, params)
+}
+
+// DecodeContentType returns the content type without the compression parameters, the codec and the logical size,
+// the codec is empty if the payload is not compressed
+func DecodeContentType(contentType string) (string, string, int64) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return contentType, "", 0
+	}
+	codec, ok := params[ContentTypeParamCodec]
+	if !ok {
+		return contentType, "", 0
+	}
+	logicalSize, err := strconv.ParseInt(params[ContentTypeParamLogicalSize], 10, 64)
+	if err != nil {
+		logicalSize = -1
+	}
+
+	delete(params, ContentTypeParamCodec)
+	delete(params, ContentTypeParamLogicalSize)
+	return mime.FormatMediaType(mediaType, params), codec, logicalSize
+}
@@ -0,0 +1,156 @@
+package compression
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/greenfield-go-sdk/types"
+)
+
+func randomPayload(t *testing.T, size int) []byte {
+	payload := make([]byte, size)
+	_, err := rand.Read(payload)
+	require.NoError(t, err)
+	return payload
+}
+
+// compress returns the compressed payload and the logical size reported by the compress reader
+func compress(t *testing.T, codec Codec, payload []byte) ([]byte, int64) {
+	reader := NewCompressReader(bytes.NewReader(payload), codec)
+	defer reader.Close()
+	compressed, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	return compressed, reader.LogicalSize()
+}
+
+func TestCompressRoundTrip(t *testing.T) {
+	cases := []struct {
+		name    string
+		payload []byte
+	}{
+		{"empty payload", nil},
+		{"one byte", []byte("a")},
+		{"repeated text", bytes.Repeat([]byte("greenfield "), 10000)},
+		{"random payload", randomPayload(t, 256*1024+7)},
+	}
+	for _, codecName := range []string{CodecGzip, CodecZstd} {
+		codec, err := GetCodec(codecName)
+		require.NoError(t, err)
+		for _, tc := range cases {
+			t.Run(codecName+"/"+tc.name, func(t *testing.T) {
+				compressed, logicalSize := compress(t, codec, tc.payload)
+				require.Equal(t, int64(len(tc.payload)), logicalSize)
+
+				// the compressed payload is hashed and uploaded separately, so it must be deterministic
+				again, _ := compress(t, codec, tc.payload)
+				require.Equal(t, compressed, again)
+
+				reader, err := codec.NewReader(bytes.NewReader(compressed))
+				require.NoError(t, err)
+				defer reader.Close()
+				plain, err := io.ReadAll(reader)
+				require.NoError(t, err)
+				require.Equal(t, len(tc.payload), len(plain))
+				require.True(t, bytes.Equal(tc.payload, plain))
+			})
+		}
+	}
+}
+
+func TestDecompressCorruptedPayload(t *testing.T) {
+	for _, codecName := range []string{CodecGzip, CodecZstd} {
+		t.Run(codecName, func(t *testing.T) {
+			codec, err := GetCodec(codecName)
+			require.NoError(t, err)
+			compressed, _ := compress(t, codec, bytes.Repeat([]byte("greenfield "), 1000))
+
+			reader, err := codec.NewReader(bytes.NewReader(compressed[:len(compressed)/2]))
+			if err == nil {
+				_, err = io.ReadAll(reader)
+				reader.Close()
+			}
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestGetCodec(t *testing.T) {
+	cases := []struct {
+		name      string
+		codec     string
+		expectErr bool
+	}{
+		{"gzip", CodecGzip, false},
+		{"zstd", CodecZstd, false},
+		{"not registered", "lz4", true},
+		{"empty name", "", true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			codec, err := GetCodec(tc.codec)
+			if tc.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.codec, codec.Name())
+		})
+	}
+}
+
+func TestEncodeContentType(t *testing.T) {
+	cases := []struct {
+		name        string
+		contentType string
+		expected    string
+	}{
+		{"plain media type", "text/plain", "text/plain; codec=gzip; logical-size=100"},
+		{"media type with parameters", "text/plain; charset=utf-8", "text/plain; charset=utf-8; codec=gzip; logical-size=100"},
+		{"existing compression parameters", "text/plain; codec=zstd; logical-size=1", "text/plain; codec=gzip; logical-size=100"},
+		{"malformed parameter", "text/plain; charset", "text/plain; codec=gzip; logical-size=100"},
+		{"duplicate parameters", "text/plain; a=1; a=2", types.ContentDefault + "; codec=gzip; logical-size=100"},
+		{"malformed media type", "text plain", types.ContentDefault + "; codec=gzip; logical-size=100"},
+		{"empty content type", "", types.ContentDefault + "; codec=gzip; logical-size=100"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			encoded := EncodeContentType(tc.contentType, CodecGzip, 100)
+			require.Equal(t, tc.expected, encoded)
+
+			_, codec, logicalSize := DecodeContentType(encoded)
+			require.Equal(t, CodecGzip, codec)
+			require.Equal(t, int64(100), logicalSize)
+		})
+	}
+}
+
+func TestDecodeContentType(t *testing.T) {
+	cases := []struct {
+		name        string
+		contentType string
+		mediaType   string
+		codec       string
+		logicalSize int64
+	}{
+		{"compressed", "text/plain; codec=gzip; logical-size=100", "text/plain", CodecGzip, 100},
+		{"compressed with other parameters", "text/plain; charset=utf-8; codec=zstd; logical-size=7", "text/plain; charset=utf-8", CodecZstd, 7},
+		{"not compressed", "text/plain; charset=utf-8", "text/plain; charset=utf-8", "", 0},
+		{"malformed logical size", "text/plain; codec=gzip; logical-size=abc", "text/plain", CodecGzip, -1},
+		{"missing logical size", "text/plain; codec=gzip", "text/plain", CodecGzip, -1},
+		{"malformed parameter", "text/plain; codec", "text/plain; codec", "", 0},
+		{"malformed media type", "text plain; codec=gzip", "text plain; codec=gzip", "", 0},
+		{"empty content type", "", "", "", 0},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			mediaType, codec, logicalSize := DecodeContentType(tc.contentType)
+			require.Equal(t, tc.mediaType, mediaType)
+			require.Equal(t, tc.codec, codec)
+			require.Equal(t, tc.logicalSize, logicalSize)
+		})
+	}
+}
@@ -0,0 +1,320 @@
+package encryption
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// The encrypted object is laid out as the header followed by the sealed chunks. Every chunk except the last one
+// holds ChunkSize bytes of plaintext and is sealed by AES-GCM independently, so that a plaintext range can be
+// decrypted from the covering chunks only.
+//
+// header: magic(4) | version(1) | chunk size(4) | plaintext size(8) | nonce prefix(8) |
+// key id length(1) | key id | wrapped key length(2) | wrapped key
+const (
+	// HeaderMagic is the magic prefix of the encrypted object
+	HeaderMagic = "GNFE"
+	// Version is the version of the encryption format
+	Version = 1
+	// DefaultChunkSize is the default plaintext size of one chunk
+	DefaultChunkSize = 64 * 1024
+	// MaxHeaderSize is the max size of the encryption header
+	MaxHeaderSize = 4 + 1 + 4 + 8 + noncePrefixSize + 1 + maxKeyIDSize + 2 + maxWrappedKeySize
+	// TagSize is the size of the authentication tag appended to each chunk
+	TagSize = 16
+
+	noncePrefixSize   = 8
+	maxKeyIDSize      = 255
+	maxWrappedKeySize = 1024
+)
+
+// Header indicates the encryption metadata stored at the beginning of the encrypted object
+type Header struct {
+	ChunkSize   uint32
+	PlainSize   uint64
+	NoncePrefix [noncePrefixSize]byte
+	KeyID       string
+	WrappedKey  []byte
+}
+
+// NewHeader generates the data key by the key provider and returns the header of the object with the plaintext size
+func NewHeader(provider KeyProvider, objectPath string, chunkSize uint32, plainSize uint64) (*Header, []byte, error) {
+	if provider == nil {
+		return nil, nil, errors.New("the key provider is nil")
+	}
+	if chunkSize == 0 {
+		chunkSize = DefaultChunkSize
+	}
+
+	dataKey, keyID, wrappedKey, err := provider.GenerateDataKey(objectPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("fail to generate data key: %s", err.Error())
+	}
+	if len(keyID) > maxKeyIDSize || len(wrappedKey) > maxWrappedKeySize {
+		return nil, nil, errors.New("the key id or the wrapped key is too long")
+	}
+
+	header := &Header{
+		ChunkSize:  chunkSize,
+		PlainSize:  plainSize,
+		KeyID:      keyID,
+		WrappedKey: wrappedKey,
+	}
+	// the chunk index is a part of the nonce and must not overflow
+	if header.ChunkCount() > math.MaxUint32 {
+		return nil, nil, errors.New("too many chunks, increase the chunk size")
+	}
+	if _, err = io.ReadFull(rand.Reader, header.NoncePrefix[:]); err != nil {
+		return nil, nil, err
+	}
+	return header, dataKey, nil
+}
+
+// Marshal encodes the header into bytes
+func (h *Header) Marshal() []byte {
+	buf := bytes.NewBuffer(make([]byte, 0, h.Size()))
+	buf.WriteString(HeaderMagic)
+	buf.WriteByte(Version)
+	_ = binary.Write(buf, binary.BigEndian, h.ChunkSize)
+	_ = binary.Write(buf, binary.BigEndian, h.PlainSize)
+	buf.Write(h.NoncePrefix[:])
+	buf.WriteByte(byte(len(h.KeyID)))
+	buf.WriteString(h.KeyID)
+	_ = binary.Write(buf, binary.BigEndian, uint16(len(h.WrappedKey)))
+	buf.Write(h.WrappedKey)
+	return buf.Bytes()
+}
+
+// ParseHeader decodes the header from the beginning of the encrypted object
+func ParseHeader(data []byte) (*Header, error) {
+	reader := bytes.NewReader(data)
+	fixed := make([]byte, 4+1+4+8+noncePrefixSize+1)
+	if _, err := io.ReadFull(reader, fixed); err != nil {
+		return nil, errors.New("the encryption header is truncated")
+	}
+	if string(fixed[:4]) != HeaderMagic {
+		return nil, errors.New("the object is not encrypted")
+	}
+	if fixed[4] != Version {
+		return nil, fmt.Errorf("unsupported encryption version %d", fixed[4])
+	}
+
+	header := &Header{
+		ChunkSize: binary.BigEndian.Uint32(fixed[5:9]),
+		PlainSize: binary.BigEndian.Uint64(fixed[9:17]),
+	}
+	copy(header.NoncePrefix[:], fixed[17:17+noncePrefixSize])
+	if header.ChunkSize == 0 {
+		return nil, errors.New("invalid chunk size in the encryption header")
+	}
+
+	keyID := make([]byte, fixed[len(fixed)-1])
+	if _, err := io.ReadFull(reader, keyID); err != nil {
+		return nil, errors.New("the encryption header is truncated")
+	}
+	header.KeyID = string(keyID)
+
+	var wrappedKeyLen uint16
+	if err := binary.Read(reader, binary.BigEndian, &wrappedKeyLen); err != nil {
+		return nil, errors.New("the encryption header is truncated")
+	}
+	if wrappedKeyLen > maxWrappedKeySize {
+		return nil, errors.New("invalid wrapped key size in the encryption header")
+	}
+	header.WrappedKey = make([]byte, wrappedKeyLen)
+	if _, err := io.ReadFull(reader, header.WrappedKey); err != nil {
+		return nil, errors.New("the encryption header is truncated")
+	}
+	return header, nil
+}
+
+// Size returns the size of the encoded header
+func (h *Header) Size() int64 {
+	return int64(4 + 1 + 4 + 8 + noncePrefixSize + 1 + len(h.KeyID) + 2 + len(h.WrappedKey))
+}
+
+// ChunkCount returns the number of the chunks, an empty payload is sealed as one empty chunk
+func (h *Header) ChunkCount() int64 {
+	if h.PlainSize == 0 {
+		return 1
+	}
+	return int64((h.PlainSize + uint64(h.ChunkSize) - 1) / uint64(h.ChunkSize))
+}
+
+// EncryptedSize returns the size of the encrypted object including the header
+func (h *Header) EncryptedSize() int64 {
+	return h.Size() + int64(h.PlainSize) + h.ChunkCount()*TagSize
+}
+
+// CiphertextRange returns the byte range [start, end] of the encrypted object which covers the plaintext range
+// [plainStart, plainEnd], and the indexes of the first and the last covering chunks
+func (h *Header) CiphertextRange(plainStart, plainEnd int64) (int64, int64, int64, int64) {
+	chunkSize := int64(h.ChunkSize)
+	sealedChunkSize := chunkSize + TagSize
+
+	firstChunk := plainStart / chunkSize
+	lastChunk := plainEnd / chunkSize
+	start := h.Size() + firstChunk*sealedChunkSize
+	end := h.Size() + (lastChunk+1)*sealedChunkSize - 1
+	if end > h.EncryptedSize()-1 {
+		end = h.EncryptedSize() - 1
+	}
+	return start, end, firstChunk, lastChunk
+}
+
+// additionalData binds the sealed chunks to the header
+func (h *Header) additionalData() []byte {
+	digest := sha256.Sum256(h.Marshal())
+	return digest[:]
+}
+
+// nonce returns the nonce of the chunk, it is the nonce prefix followed by the chunk index
+func (h *Header) nonce(chunkIndex int64) []byte {
+	nonce := make([]byte, noncePrefixSize+4)
+	copy(nonce, h.NoncePrefix[:])
+	binary.BigEndian.PutUint32(nonce[noncePrefixSize:], uint32(chunkIndex))
+	return nonce
+}
+
+// chunkPlainSize returns the plaintext size of the chunk
+func (h *Header) chunkPlainSize(chunkIndex int64) int64 {
+	if chunkIndex == h.ChunkCount()-1 {
+		return int64(h.PlainSize) - chunkIndex*int64(h.ChunkSize)
+	}
+	return int64(h.ChunkSize)
+}
+
+// encryptReader emits the header and then the sealed chunks of the plaintext
+type encryptReader struct {
+	reader     io.Reader
+	header     *Header
+	aead       cipher.AEAD
+	aad        []byte
+	chunkIndex int64
+	plain      []byte
+	buf        []byte
+	err        error
+}
+
+// NewEncryptReader returns the reader of the encrypted object, the plaintext read from reader
+// should be exactly header.PlainSize bytes
+func NewEncryptReader(reader io.Reader, header *Header, dataKey []byte) (io.Reader, error) {
+	aead, err := newGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return &encryptReader{
+		reader: reader,
+		header: header,
+		aead:   aead,
+		aad:    header.additionalData(),
+		plain:  make([]byte, header.ChunkSize),
+		buf:    header.Marshal(),
+	}, nil
+}
+
+func (r *encryptReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		r.sealNextChunk()
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+func (r *encryptReader) sealNextChunk() {
+	if r.chunkIndex == r.header.ChunkCount() {
+		// make sure the plaintext is not longer than the size in header
+		if n, _ := r.reader.Read(make([]byte, 1)); n > 0 {
+			r.err = errors.New("the payload is longer than the plaintext size in the encryption header")
+			return
+		}
+		r.err = io.EOF
+		return
+	}
+
+	size := r.header.chunkPlainSize(r.chunkIndex)
+	if _, err := io.ReadFull(r.reader, r.plain[:size]); err != nil {
+		r.err = fmt.Errorf("fail to read the payload of chunk %d: %s", r.chunkIndex, err.Error())
+		return
+	}
+	r.buf = r.aead.Seal(r.buf[:0], r.header.nonce(r.chunkIndex), r.plain[:size], r.aad)
+	r.chunkIndex++
+}
+
+// decryptReader opens the sealed chunks from the first chunk to the last chunk
+type decryptReader struct {
+	reader     io.Reader
+	header     *Header
+	aead       cipher.AEAD
+	aad        []byte
+	chunkIndex int64
+	lastChunk  int64
+	sealed     []byte
+	buf        []byte
+	err        error
+}
+
+// NewDecryptReader returns the reader of the plaintext, the reader should read the sealed chunks from firstChunk
+// to lastChunk, the chunks are authenticated one by one
+func NewDecryptReader(reader io.Reader, header *Header, dataKey []byte, firstChunk, lastChunk int64) (io.Reader, error) {
+	if firstChunk < 0 || lastChunk < firstChunk || lastChunk >= header.ChunkCount() {
+		return nil, fmt.Errorf("invalid chunk range [%d, %d]", firstChunk, lastChunk)
+	}
+	aead, err := newGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return &decryptReader{
+		reader:     reader,
+		header:     header,
+		aead:       aead,
+		aad:        header.additionalData(),
+		chunkIndex: firstChunk,
+		lastChunk:  lastChunk,
+		sealed:     make([]byte, int64(header.ChunkSize)+TagSize),
+	}, nil
+}
+
+func (r *decryptReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		r.openNextChunk()
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+func (r *decryptReader) openNextChunk() {
+	if r.chunkIndex > r.lastChunk {
+		r.err = io.EOF
+		return
+	}
+
+	size := r.header.chunkPlainSize(r.chunkIndex) + TagSize
+	if _, err := io.ReadFull(r.reader, r.sealed[:size]); err != nil {
+		r.err = fmt.Errorf("the encrypted chunk %d is truncated: %s", r.chunkIndex, err.Error())
+		return
+	}
+
+	plain, err := r.aead.Open(r.sealed[:0], r.header.nonce(r.chunkIndex), r.sealed[:size], r.aad)
+	if err != nil {
+		r.err = fmt.Errorf("fail to decrypt chunk %d: %s", r.chunkIndex, err.Error())
+		return
+	}
+	r.buf = plain
+	r.chunkIndex++
+}
@@ -0,0 +1,268 @@
+package encryption
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testObjectPath = "bucket/object"
+
+func newTestProvider(t *testing.T, keyID string) *MasterKeyProvider {
+	masterKey := make([]byte, 32)
+	_, err := rand.Read(masterKey)
+	require.NoError(t, err)
+	provider, err := NewMasterKeyProvider(keyID, masterKey)
+	require.NoError(t, err)
+	return provider
+}
+
+func randomPayload(t *testing.T, size int) []byte {
+	payload := make([]byte, size)
+	_, err := rand.Read(payload)
+	require.NoError(t, err)
+	return payload
+}
+
+// encrypt returns the header, the data key and the encrypted object of the payload
+func encrypt(t *testing.T, provider KeyProvider, chunkSize uint32, payload []byte) (*Header, []byte, []byte) {
+	header, dataKey, err := NewHeader(provider, testObjectPath, chunkSize, uint64(len(payload)))
+	require.NoError(t, err)
+	reader, err := NewEncryptReader(bytes.NewReader(payload), header, dataKey)
+	require.NoError(t, err)
+	encrypted, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	require.Equal(t, header.EncryptedSize(), int64(len(encrypted)))
+	return header, dataKey, encrypted
+}
+
+func TestEncryptDecryptRoundTrip(t *testing.T) {
+	provider := newTestProvider(t, "master")
+	cases := []struct {
+		name      string
+		chunkSize uint32
+		size      int
+	}{
+		{"empty object", 16, 0},
+		{"one byte", 16, 1},
+		{"less than one chunk", 16, 15},
+		{"exactly one chunk", 16, 16},
+		{"one byte over the chunk boundary", 16, 17},
+		{"exactly multiple chunks", 16, 64},
+		{"multiple chunks with the partial last chunk", 16, 70},
+		{"default chunk size", 0, DefaultChunkSize*2 + 100},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			payload := randomPayload(t, tc.size)
+			header, _, encrypted := encrypt(t, provider, tc.chunkSize, payload)
+
+			parsed, err := ParseHeader(encrypted)
+			require.NoError(t, err)
+			require.Equal(t, header, parsed)
+			dataKey, err := provider.DecryptDataKey(testObjectPath, parsed.KeyID, parsed.WrappedKey)
+			require.NoError(t, err)
+
+			reader, err := NewDecryptReader(bytes.NewReader(encrypted[parsed.Size():]), parsed, dataKey, 0, parsed.ChunkCount()-1)
+			require.NoError(t, err)
+			plain, err := io.ReadAll(reader)
+			require.NoError(t, err)
+			require.Equal(t, len(payload), len(plain))
+			require.True(t, bytes.Equal(payload, plain))
+		})
+	}
+}
+
+func TestCiphertextRange(t *testing.T) {
+	provider := newTestProvider(t, "master")
+	const chunkSize = 16
+	payload := randomPayload(t, 100)
+	header, dataKey, encrypted := encrypt(t, provider, chunkSize, payload)
+
+	cases := []struct {
+		name                  string
+		plainStart, plainEnd  int64
+		firstChunk, lastChunk int64
+	}{
+		{"first byte", 0, 0, 0, 0},
+		{"inside one chunk", 3, 10, 0, 0},
+		{"whole chunk", 16, 31, 1, 1},
+		{"across the chunk boundary", 15, 16, 0, 1},
+		{"across multiple chunks", 5, 60, 0, 3},
+		{"inside the partial last chunk", 97, 99, 6, 6},
+		{"whole object", 0, 99, 0, 6},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			start, end, firstChunk, lastChunk := header.CiphertextRange(tc.plainStart, tc.plainEnd)
+			require.Equal(t, tc.firstChunk, firstChunk)
+			require.Equal(t, tc.lastChunk, lastChunk)
+			require.True(t, end < int64(len(encrypted)))
+
+			reader, err := NewDecryptReader(bytes.NewReader(encrypted[start:end+1]), header, dataKey, firstChunk, lastChunk)
+			require.NoError(t, err)
+			plain, err := io.ReadAll(reader)
+			require.NoError(t, err)
+			offset := tc.plainStart - firstChunk*chunkSize
+			require.Equal(t, payload[tc.plainStart:tc.plainEnd+1], plain[offset:offset+tc.plainEnd-tc.plainStart+1])
+		})
+	}
+}
+
+func TestDecryptTamperedObject(t *testing.T) {
+	provider := newTestProvider(t, "master")
+	payload := randomPayload(t, 50)
+	header, dataKey, encrypted := encrypt(t, provider, 16, payload)
+	headerSize := header.Size()
+
+	cases := []struct {
+		name   string
+		tamper func(encrypted []byte) []byte
+	}{
+		{"flip a ciphertext byte", func(encrypted []byte) []byte {
+			encrypted[headerSize+20] ^= 0x01
+			return encrypted
+		}},
+		{"flip a tag byte", func(encrypted []byte) []byte {
+			encrypted[len(encrypted)-1] ^= 0x01
+			return encrypted
+		}},
+		{"swap two chunks", func(encrypted []byte) []byte {
+			sealed := int64(16 + TagSize)
+			first := append([]byte{}, encrypted[headerSize:headerSize+sealed]...)
+			copy(encrypted[headerSize:], encrypted[headerSize+sealed:headerSize+2*sealed])
+			copy(encrypted[headerSize+sealed:], first)
+			return encrypted
+		}},
+		{"truncate the last chunk", func(encrypted []byte) []byte {
+			return encrypted[:len(encrypted)-1]
+		}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tampered := tc.tamper(append([]byte{}, encrypted...))
+			reader, err := NewDecryptReader(bytes.NewReader(tampered[headerSize:]), header, dataKey, 0, header.ChunkCount()-1)
+			require.NoError(t, err)
+			_, err = io.ReadAll(reader)
+			require.Error(t, err)
+		})
+	}
+
+	t.Run("modify the header", func(t *testing.T) {
+		// the header is authenticated as the additional data of every chunk
+		modified := *header
+		modified.NoncePrefix[0] ^= 0x01
+		reader, err := NewDecryptReader(bytes.NewReader(encrypted[headerSize:]), &modified, dataKey, 0, header.ChunkCount()-1)
+		require.NoError(t, err)
+		_, err = io.ReadAll(reader)
+		require.Error(t, err)
+	})
+}
+
+func TestEncryptPayloadSizeMismatch(t *testing.T) {
+	provider := newTestProvider(t, "master")
+	cases := []struct {
+		name        string
+		payloadSize int
+		headerSize  uint64
+	}{
+		{"payload shorter than header", 10, 20},
+		{"payload longer than header", 20, 10},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			header, dataKey, err := NewHeader(provider, testObjectPath, 16, tc.headerSize)
+			require.NoError(t, err)
+			reader, err := NewEncryptReader(bytes.NewReader(randomPayload(t, tc.payloadSize)), header, dataKey)
+			require.NoError(t, err)
+			_, err = io.ReadAll(reader)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestUnwrapDataKey(t *testing.T) {
+	provider := newTestProvider(t, "master")
+	dataKey, keyID, wrappedKey, err := provider.GenerateDataKey(testObjectPath)
+	require.NoError(t, err)
+	require.Len(t, dataKey, DataKeySize)
+
+	sameIDProvider := newTestProvider(t, "master")
+	otherIDProvider := newTestProvider(t, "other")
+	tamperedKey := append([]byte{}, wrappedKey...)
+	tamperedKey[len(tamperedKey)-1] ^= 0x01
+
+	cases := []struct {
+		name       string
+		provider   *MasterKeyProvider
+		objectPath string
+		keyID      string
+		wrappedKey []byte
+		expectErr  bool
+	}{
+		{"correct master key", provider, testObjectPath, keyID, wrappedKey, false},
+		{"wrong master key with the same key id", sameIDProvider, testObjectPath, keyID, wrappedKey, true},
+		{"wrong key id", otherIDProvider, testObjectPath, keyID, wrappedKey, true},
+		{"copied to another object", provider, "another-bucket/another-object", keyID, wrappedKey, false},
+		{"tampered wrapped key", provider, testObjectPath, keyID, tamperedKey, true},
+		{"truncated wrapped key", provider, testObjectPath, keyID, wrappedKey[:4], true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			unwrapped, err := tc.provider.DecryptDataKey(tc.objectPath, tc.keyID, tc.wrappedKey)
+			if tc.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, dataKey, unwrapped)
+		})
+	}
+}
+
+func TestParseHeader(t *testing.T) {
+	provider := newTestProvider(t, "master")
+	header, _, encrypted := encrypt(t, provider, 16, randomPayload(t, 10))
+
+	cases := []struct {
+		name      string
+		data      []byte
+		expectErr bool
+	}{
+		{"valid header", encrypted[:header.Size()], false},
+		{"truncated header", encrypted[:header.Size()-1], true},
+		{"wrong magic", append([]byte("XXXX"), encrypted[4:]...), true},
+		{"empty data", nil, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			parsed, err := ParseHeader(tc.data)
+			if tc.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, header, parsed)
+		})
+	}
+}
+
+func TestDecryptCopiedObject(t *testing.T) {
+	// the encrypted object is copied byte for byte by CopyObject, MoveObject and MigrateBucket
+	provider := newTestProvider(t, "master")
+	payload := randomPayload(t, 100)
+	_, _, encrypted := encrypt(t, provider, 16, payload)
+
+	header, err := ParseHeader(encrypted)
+	require.NoError(t, err)
+	dataKey, err := provider.DecryptDataKey("dst-bucket/dst-object", header.KeyID, header.WrappedKey)
+	require.NoError(t, err)
+	reader, err := NewDecryptReader(bytes.NewReader(encrypted[header.Size():]), header, dataKey, 0, header.ChunkCount()-1)
+	require.NoError(t, err)
+	plain, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	require.Equal(t, payload, plain)
+}
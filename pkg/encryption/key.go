@@ -0,0 +1,122 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// DataKeySize is the size of the AES-256 data key generated for each object
+const DataKeySize = 32
+
+// KeyProvider provides the data key to encrypt and decrypt the object payload.
+// The key id and the wrapped key are stored in the encryption header of the object.
+// objectPath indicates "bucketName/objectName" of the object for auditing only, the wrapped key must not be bound to it,
+// since the encrypted object is copied byte for byte by CopyObject, MoveObject and MigrateBucket.
+type KeyProvider interface {
+	// GenerateDataKey returns the data key to encrypt the object, with the key id and the wrapped key to store in header
+	GenerateDataKey(objectPath string) (dataKey []byte, keyID string, wrappedKey []byte, err error)
+	// DecryptDataKey recovers the data key from the key id and the wrapped key stored in header
+	DecryptDataKey(objectPath string, keyID string, wrappedKey []byte) ([]byte, error)
+}
+
+// StaticKeyProvider encrypts all the objects with the same key, nothing but the key id is stored in header
+type StaticKeyProvider struct {
+	keyID string
+	key   []byte
+}
+
+// NewStaticKeyProvider returns the key provider with a static AES key, the key size should be 16, 24 or 32 bytes
+func NewStaticKeyProvider(keyID string, key []byte) (*StaticKeyProvider, error) {
+	if err := checkKey(keyID, key); err != nil {
+		return nil, err
+	}
+	return &StaticKeyProvider{keyID: keyID, key: key}, nil
+}
+
+// GenerateDataKey returns the static key
+func (p *StaticKeyProvider) GenerateDataKey(objectPath string) ([]byte, string, []byte, error) {
+	return p.key, p.keyID, nil, nil
+}
+
+// DecryptDataKey returns the static key if the key id matches
+func (p *StaticKeyProvider) DecryptDataKey(objectPath string, keyID string, wrappedKey []byte) ([]byte, error) {
+	if keyID != p.keyID {
+		return nil, fmt.Errorf("the object is encrypted by key %s, not key %s", keyID, p.keyID)
+	}
+	return p.key, nil
+}
+
+// MasterKeyProvider generates a random data key for each object and wraps it by the master key with AES-GCM,
+// the key id is authenticated with the wrapped key so that it can not be unwrapped under another key id
+type MasterKeyProvider struct {
+	keyID string
+	aead  cipher.AEAD
+}
+
+// NewMasterKeyProvider returns the key provider with the master AES key, the key size should be 16, 24 or 32 bytes
+func NewMasterKeyProvider(keyID string, masterKey []byte) (*MasterKeyProvider, error) {
+	if err := checkKey(keyID, masterKey); err != nil {
+		return nil, err
+	}
+	aead, err := newGCM(masterKey)
+	if err != nil {
+		return nil, err
+	}
+	return &MasterKeyProvider{keyID: keyID, aead: aead}, nil
+}
+
+// GenerateDataKey generates a random data key and wraps it by the master key
+func (p *MasterKeyProvider) GenerateDataKey(objectPath string) ([]byte, string, []byte, error) {
+	dataKey := make([]byte, DataKeySize)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return nil, "", nil, err
+	}
+	nonce := make([]byte, p.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, "", nil, err
+	}
+
+	wrappedKey := p.aead.Seal(nonce, nonce, dataKey, []byte(p.keyID))
+	return dataKey, p.keyID, wrappedKey, nil
+}
+
+// DecryptDataKey unwraps the data key by the master key
+func (p *MasterKeyProvider) DecryptDataKey(objectPath string, keyID string, wrappedKey []byte) ([]byte, error) {
+	if keyID != p.keyID {
+		return nil, fmt.Errorf("the object is encrypted by master key %s, not master key %s", keyID, p.keyID)
+	}
+	nonceSize := p.aead.NonceSize()
+	if len(wrappedKey) < nonceSize {
+		return nil, errors.New("the wrapped data key is too short")
+	}
+
+	dataKey, err := p.aead.Open(nil, wrappedKey[:nonceSize], wrappedKey[nonceSize:], []byte(keyID))
+	if err != nil {
+		return nil, fmt.Errorf("fail to unwrap the data key: %s", err.Error())
+	}
+	return dataKey, nil
+}
+
+func checkKey(keyID string, key []byte) error {
+	if len(keyID) > maxKeyIDSize {
+		return fmt.Errorf("the key id should be no more than %d bytes", maxKeyIDSize)
+	}
+	switch len(key) {
+	case 16, 24, 32:
+		return nil
+	default:
+		return errors.New("the AES key size should be 16, 24 or 32 bytes")
+	}
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,143 @@
+package ratelimit
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+)
+
+// Limiter is a token bucket which limits the bytes transferred per second, it is safe for concurrent use
+// and can be shared by the transfers which should be limited together.
+type Limiter struct {
+	mu        sync.Mutex
+	rate      float64
+	burst     int64
+	tokens    float64
+	lastCheck time.Time
+
+	// now and newTimer are replaced by the tests to control the clock
+	now      func() time.Time
+	newTimer func(d time.Duration) (<-chan time.Time, func() bool)
+}
+
+func newTimer(d time.Duration) (<-chan time.Time, func() bool) {
+	timer := time.NewTimer(d)
+	return timer.C, timer.Stop
+}
+
+// NewLimiter returns the limiter which allows bytesPerSecond bytes per second with the burst size,
+// bytesPerSecond should be positive and the burst size is the same as the rate if it is not positive
+func NewLimiter(bytesPerSecond int64, burst int64) *Limiter {
+	if burst <= 0 {
+		burst = bytesPerSecond
+	}
+	return &Limiter{
+		rate:      float64(bytesPerSecond),
+		burst:     burst,
+		tokens:    float64(burst),
+		lastCheck: time.Now(),
+		now:       time.Now,
+		newTimer:  newTimer,
+	}
+}
+
+// Burst returns the max bytes which can be taken at once
+func (l *Limiter) Burst() int64 {
+	return l.burst
+}
+
+// WaitN blocks until n bytes are allowed or the context is done, n larger than the burst size is taken in batches
+func (l *Limiter) WaitN(ctx context.Context, n int64) error {
+	for n > 0 {
+		take := n
+		if take > l.burst {
+			take = l.burst
+		}
+		if err := l.wait(ctx, take); err != nil {
+			return err
+		}
+		n -= take
+	}
+	return nil
+}
+
+// wait reserves n tokens and sleeps until the debt is paid off, n should be no more than the burst size
+func (l *Limiter) wait(ctx context.Context, n int64) error {
+	l.mu.Lock()
+	now := l.now()
+	l.tokens += now.Sub(l.lastCheck).Seconds() * l.rate
+	if l.tokens > float64(l.burst) {
+		l.tokens = float64(l.burst)
+	}
+	l.lastCheck = now
+	l.tokens -= float64(n)
+	delay := time.Duration(0)
+	if l.tokens < 0 {
+		delay = time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+	l.mu.Unlock()
+
+	if delay == 0 {
+		return nil
+	}
+	timer, stop := l.newTimer(delay)
+	defer stop()
+	select {
+	case <-timer:
+		return nil
+	case <-ctx.Done():
+		// give back the tokens which are not used
+		l.mu.Lock()
+		l.tokens += float64(n)
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// Reader limits the read speed of the underlying reader by all the non-nil limiters
+type Reader struct {
+	ctx      context.Context
+	reader   io.Reader
+	limiters []*Limiter
+	maxRead  int64
+}
+
+// NewReader returns the reader limited by the limiters, the nil limiters are ignored
+func NewReader(ctx context.Context, reader io.Reader, limiters ...*Limiter) *Reader {
+	r := &Reader{ctx: ctx, reader: reader}
+	for _, limiter := range limiters {
+		if limiter == nil {
+			continue
+		}
+		r.limiters = append(r.limiters, limiter)
+		// read no more than the smallest burst at once to keep the transfer smooth
+		if r.maxRead == 0 || limiter.Burst() < r.maxRead {
+			r.maxRead = limiter.Burst()
+		}
+	}
+	return r
+}
+
+func (r *Reader) Read(p []byte) (int, error) {
+	if r.maxRead > 0 && int64(len(p)) > r.maxRead {
+		p = p[:r.maxRead]
+	}
+	n, err := r.reader.Read(p)
+	if n > 0 {
+		for _, limiter := range r.limiters {
+			if waitErr := limiter.WaitN(r.ctx, int64(n)); waitErr != nil {
+				return n, waitErr
+			}
+		}
+	}
+	return n, err
+}
+
+// Close closes the underlying reader if it is an io.Closer
+func (r *Reader) Close() error {
+	if closer, ok := r.reader.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
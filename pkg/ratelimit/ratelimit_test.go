@@ -0,0 +1,279 @@
+package ratelimit
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeTimer struct {
+	deadline time.Time
+	c        chan time.Time
+}
+
+// fakeClock is the controllable clock of the limiter, the timers fire only when the clock is advanced past their
+// deadlines, or immediately by advancing the clock if autoAdvance is set
+type fakeClock struct {
+	mu          sync.Mutex
+	now         time.Time
+	timers      []*fakeTimer
+	started     chan time.Duration
+	autoAdvance bool
+}
+
+func newFakeClock(autoAdvance bool) *fakeClock {
+	return &fakeClock{now: time.Unix(1000, 0), started: make(chan time.Duration, 100), autoAdvance: autoAdvance}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) NewTimer(d time.Duration) (<-chan time.Time, func() bool) {
+	c.mu.Lock()
+	timer := &fakeTimer{deadline: c.now.Add(d), c: make(chan time.Time, 1)}
+	c.timers = append(c.timers, timer)
+	c.mu.Unlock()
+
+	c.started <- d
+	if c.autoAdvance {
+		c.Advance(d)
+	}
+	return timer.c, func() bool { return true }
+}
+
+// Advance moves the clock forward and fires the expired timers
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	pending := c.timers[:0]
+	for _, timer := range c.timers {
+		if timer.deadline.After(c.now) {
+			pending = append(pending, timer)
+			continue
+		}
+		timer.c <- c.now
+	}
+	c.timers = pending
+}
+
+// waitTimer returns the duration of the next started timer
+func (c *fakeClock) waitTimer(t *testing.T) time.Duration {
+	select {
+	case d := <-c.started:
+		return d
+	case <-time.After(5 * time.Second):
+		t.Fatal("no timer is started")
+		return 0
+	}
+}
+
+// requireNoTimer checks no timer is started since the last check
+func (c *fakeClock) requireNoTimer(t *testing.T) {
+	select {
+	case d := <-c.started:
+		t.Fatalf("unexpected timer of %s", d)
+	default:
+	}
+}
+
+func newTestLimiter(clock *fakeClock, bytesPerSecond int64, burst int64) *Limiter {
+	limiter := NewLimiter(bytesPerSecond, burst)
+	limiter.now = clock.Now
+	limiter.newTimer = clock.NewTimer
+	limiter.lastCheck = clock.Now()
+	return limiter
+}
+
+// waitAsync calls WaitN in background and returns the channel of its result
+func waitAsync(ctx context.Context, limiter *Limiter, n int64) chan error {
+	done := make(chan error, 1)
+	go func() {
+		done <- limiter.WaitN(ctx, n)
+	}()
+	return done
+}
+
+func TestLimiterBurst(t *testing.T) {
+	cases := []struct {
+		name          string
+		rate, burst   int64
+		expectedBurst int64
+	}{
+		{"explicit burst", 100, 50, 50},
+		{"default burst", 100, 0, 100},
+		{"negative burst", 100, -1, 100},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			clock := newFakeClock(false)
+			limiter := newTestLimiter(clock, tc.rate, tc.burst)
+			require.Equal(t, tc.expectedBurst, limiter.Burst())
+
+			// the full bucket is taken at once without waiting
+			require.NoError(t, limiter.WaitN(context.Background(), tc.expectedBurst))
+			clock.requireNoTimer(t)
+
+			// the next byte waits for the refill of one byte
+			done := waitAsync(context.Background(), limiter, 1)
+			require.Equal(t, time.Second/time.Duration(tc.rate), clock.waitTimer(t))
+			clock.Advance(time.Second / time.Duration(tc.rate))
+			require.NoError(t, <-done)
+		})
+	}
+}
+
+func TestLimiterRefill(t *testing.T) {
+	cases := []struct {
+		name    string
+		elapsed time.Duration
+		take    int64
+		delay   time.Duration
+	}{
+		{"no refill", 0, 10, 100 * time.Millisecond},
+		{"partial refill", 250 * time.Millisecond, 25, 0},
+		{"partial refill with debt", 250 * time.Millisecond, 35, 100 * time.Millisecond},
+		{"full refill", 500 * time.Millisecond, 50, 0},
+		{"refill is capped by the burst", 10 * time.Second, 60, 100 * time.Millisecond},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			clock := newFakeClock(true)
+			limiter := newTestLimiter(clock, 100, 50)
+			require.NoError(t, limiter.WaitN(context.Background(), 50))
+
+			clock.Advance(tc.elapsed)
+			start := clock.Now()
+			require.NoError(t, limiter.WaitN(context.Background(), tc.take))
+			require.Equal(t, tc.delay, clock.Now().Sub(start))
+		})
+	}
+}
+
+func TestLimiterWaitLargerThanBurst(t *testing.T) {
+	clock := newFakeClock(false)
+	limiter := newTestLimiter(clock, 100, 50)
+
+	// 120 bytes are taken as 50, 50 and 20 bytes
+	done := waitAsync(context.Background(), limiter, 120)
+	require.Equal(t, 500*time.Millisecond, clock.waitTimer(t))
+	clock.Advance(500 * time.Millisecond)
+	require.Equal(t, 200*time.Millisecond, clock.waitTimer(t))
+	clock.Advance(200 * time.Millisecond)
+	require.NoError(t, <-done)
+	clock.requireNoTimer(t)
+}
+
+func TestLimiterBlocksUntilCanceled(t *testing.T) {
+	clock := newFakeClock(false)
+	limiter := newTestLimiter(clock, 100, 50)
+	require.NoError(t, limiter.WaitN(context.Background(), 50))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := waitAsync(ctx, limiter, 10)
+	require.Equal(t, 100*time.Millisecond, clock.waitTimer(t))
+
+	// the wait is blocked until the context is canceled
+	clock.Advance(50 * time.Millisecond)
+	select {
+	case err := <-done:
+		t.Fatalf("wait returns before the timer fires: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	cancel()
+	require.ErrorIs(t, <-done, context.Canceled)
+
+	// the tokens of the canceled wait are given back, 5 bytes are refilled in 50ms
+	require.NoError(t, limiter.WaitN(context.Background(), 5))
+	clock.requireNoTimer(t)
+}
+
+func TestLimiterCanceledContext(t *testing.T) {
+	clock := newFakeClock(false)
+	limiter := newTestLimiter(clock, 100, 50)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// the available tokens are taken without checking the context
+	require.NoError(t, limiter.WaitN(ctx, 50))
+	require.ErrorIs(t, limiter.WaitN(ctx, 1), context.Canceled)
+}
+
+type countReader struct {
+	reader io.Reader
+	reads  []int
+	closed bool
+}
+
+func (r *countReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if n > 0 {
+		r.reads = append(r.reads, n)
+	}
+	return n, err
+}
+
+func (r *countReader) Close() error {
+	r.closed = true
+	return nil
+}
+
+func TestReader(t *testing.T) {
+	payload := bytes.Repeat([]byte("greenfield"), 10)
+	cases := []struct {
+		name    string
+		bursts  []int64
+		reads   []int
+		elapsed time.Duration
+	}{
+		{"no limiter", nil, []int{100}, 0},
+		{"one limiter", []int64{30}, []int{30, 30, 30, 10}, 70 * time.Second / 30},
+		{"smallest burst limits the read size", []int64{30, 40}, []int{30, 30, 30, 10}, 70 * time.Second / 30},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			clock := newFakeClock(true)
+			limiters := []*Limiter{nil}
+			for _, burst := range tc.bursts {
+				limiters = append(limiters, newTestLimiter(clock, 30, burst))
+			}
+			source := &countReader{reader: bytes.NewReader(payload)}
+			reader := NewReader(context.Background(), source, limiters...)
+
+			start := clock.Now()
+			data, err := io.ReadAll(reader)
+			require.NoError(t, err)
+			require.Equal(t, payload, data)
+			require.Equal(t, tc.reads, source.reads)
+			require.Equal(t, tc.elapsed, clock.Now().Sub(start))
+
+			require.NoError(t, reader.Close())
+			require.True(t, source.closed)
+		})
+	}
+}
+
+func TestReaderCanceled(t *testing.T) {
+	clock := newFakeClock(false)
+	limiter := newTestLimiter(clock, 10, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	reader := NewReader(ctx, bytes.NewReader(make([]byte, 100)), limiter)
+
+	buf := make([]byte, 100)
+	n, err := reader.Read(buf)
+	require.NoError(t, err)
+	require.Equal(t, 10, n)
+
+	cancel()
+	n, err = reader.Read(buf)
+	require.Equal(t, 10, n)
+	require.ErrorIs(t, err, context.Canceled)
+}
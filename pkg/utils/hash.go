@@ -0,0 +1,146 @@
+package utils
+
+import (
+	"errors"
+	"io"
+	"runtime"
+	"sync"
+
+	hashlib "github.com/bnb-chain/greenfield-common/go/hash"
+	"github.com/bnb-chain/greenfield-common/go/redundancy"
+	storageTypes "github.com/bnb-chain/greenfield/x/storage/types"
+	"github.com/rs/zerolog/log"
+
+	"github.com/bnb-chain/greenfield-go-sdk/types"
+)
+
+// segmentJob indicates one segment of the payload to be hashed
+type segmentJob struct {
+	index int
+	data  []byte
+}
+
+// segmentHash indicates the checksum of the segment and the checksums of its erasure encoded pieces
+type segmentHash struct {
+	checksum    []byte
+	pieceHashes [][]byte
+}
+
+// ComputeHashRootsWithParams return the integrity hash, content size and the redundancy type of the payload
+// with the given redundancy params, it does not access the chain and is safe for concurrent use.
+// The segments are hashed and erasure encoded in parallel, params.Parallelism limits the number of the workers.
+func ComputeHashRootsWithParams(reader io.Reader, redundancyType storageTypes.RedundancyType,
+	params types.ComputeHashOptions,
+) ([][]byte, int64, storageTypes.RedundancyType, error) {
+	if reader == nil {
+		return nil, 0, redundancyType, errors.New("fail to compute hash, reader is nil")
+	}
+	if params.SegmentSize == 0 || params.DataShards == 0 {
+		return nil, 0, redundancyType, errors.New("fail to compute hash, the segment size and data shards should be positive")
+	}
+	isReplicaType := redundancyType == storageTypes.REDUNDANCY_REPLICA_TYPE
+	ecShards := int(params.DataShards + params.ParityShards)
+
+	parallelism := params.Parallelism
+	if parallelism <= 0 {
+		parallelism = runtime.NumCPU()
+	}
+
+	var (
+		mu       sync.Mutex
+		hashes   = make(map[int]*segmentHash)
+		firstErr error
+		wg       sync.WaitGroup
+	)
+	jobs := make(chan segmentJob, parallelism)
+	for i := 0; i < parallelism; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				hash := &segmentHash{checksum: hashlib.GenerateChecksum(job.data)}
+				var err error
+				if !isReplicaType {
+					hash.pieceHashes, err = hashPieces(job.data, int(params.DataShards), int(params.ParityShards))
+				}
+
+				mu.Lock()
+				if err != nil && firstErr == nil {
+					firstErr = err
+				}
+				hashes[job.index] = hash
+				mu.Unlock()
+			}
+		}()
+	}
+
+	// read the payload segment by segment and dispatch the segments to the workers
+	contentLen := int64(0)
+	segCount := 0
+	var readErr error
+	for {
+		seg := make([]byte, params.SegmentSize)
+		n, err := io.ReadFull(reader, seg)
+		if n > 0 {
+			contentLen += int64(n)
+			jobs <- segmentJob{index: segCount, data: seg[:n]}
+			segCount++
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			log.Error().Msg("fail to read content: " + err.Error())
+			readErr = err
+			break
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if readErr != nil {
+		return nil, 0, redundancyType, readErr
+	}
+	if firstErr != nil {
+		return nil, 0, redundancyType, firstErr
+	}
+
+	segChecksumList := make([][]byte, 0, segCount)
+	pieceChecksumList := make([][][]byte, ecShards)
+	for i := 0; i < segCount; i++ {
+		segChecksumList = append(segChecksumList, hashes[i].checksum)
+		for j, pieceHash := range hashes[i].pieceHashes {
+			pieceChecksumList[j] = append(pieceChecksumList[j], pieceHash)
+		}
+	}
+
+	hashList := make([][]byte, ecShards+1)
+	hashList[0] = hashlib.GenerateIntegrityHash(segChecksumList)
+	for i := 0; i < ecShards; i++ {
+		if isReplicaType {
+			// every secondary SP stores a full copy of the segments
+			hashList[i+1] = hashList[0]
+		} else {
+			hashList[i+1] = hashlib.GenerateIntegrityHash(pieceChecksumList[i])
+		}
+	}
+
+	if isReplicaType {
+		return hashList, contentLen, storageTypes.REDUNDANCY_REPLICA_TYPE, nil
+	}
+	return hashList, contentLen, storageTypes.REDUNDANCY_EC_TYPE, nil
+}
+
+// hashPieces erasure encodes the segment and returns the checksum of each piece
+func hashPieces(data []byte, dataShards, parityShards int) ([][]byte, error) {
+	encodeShards, err := redundancy.EncodeRawSegment(data, dataShards, parityShards)
+	if err != nil {
+		return nil, err
+	}
+
+	pieceHashes := make([][]byte, len(encodeShards))
+	for i, shard := range encodeShards {
+		pieceHashes[i] = hashlib.GenerateChecksum(shard)
+	}
+	return pieceHashes, nil
+}
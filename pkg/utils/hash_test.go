@@ -0,0 +1,82 @@
+package utils
+
+import (
+	"bytes"
+	"crypto/rand"
+	"fmt"
+	"testing"
+
+	hashlib "github.com/bnb-chain/greenfield-common/go/hash"
+	storageTypes "github.com/bnb-chain/greenfield/x/storage/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/greenfield-go-sdk/types"
+)
+
+func TestComputeHashRootsWithParams(t *testing.T) {
+	const segmentSize = 1024
+	params := types.ComputeHashOptions{SegmentSize: segmentSize, DataShards: 4, ParityShards: 2}
+
+	sizes := []struct {
+		name string
+		size int
+	}{
+		{"empty payload", 0},
+		{"one byte", 1},
+		{"exactly one segment", segmentSize},
+		{"one segment plus one byte", segmentSize + 1},
+		{"several segments", segmentSize*5 + 100},
+		{"exactly several segments", segmentSize * 4},
+	}
+	for _, redundancyType := range []storageTypes.RedundancyType{storageTypes.REDUNDANCY_EC_TYPE, storageTypes.REDUNDANCY_REPLICA_TYPE} {
+		for _, parallelism := range []int{1, 4} {
+			for _, tc := range sizes {
+				t.Run(fmt.Sprintf("%s/parallelism %d/%s", redundancyType.String(), parallelism, tc.name), func(t *testing.T) {
+					payload := make([]byte, tc.size)
+					_, err := rand.Read(payload)
+					require.NoError(t, err)
+
+					expected, expectedSize, _, err := hashlib.ComputeIntegrityHash(bytes.NewReader(payload), segmentSize,
+						int(params.DataShards), int(params.ParityShards))
+					require.NoError(t, err)
+
+					params.Parallelism = parallelism
+					hashes, size, gotType, err := ComputeHashRootsWithParams(bytes.NewReader(payload), redundancyType, params)
+					require.NoError(t, err)
+					require.Equal(t, expectedSize, size)
+					require.Equal(t, redundancyType, gotType)
+					require.Len(t, hashes, len(expected))
+
+					if redundancyType == storageTypes.REDUNDANCY_EC_TYPE {
+						require.Equal(t, expected, hashes)
+						return
+					}
+					// hashlib computes the EC hashes only, the secondary SPs of the replica type store the full segments
+					require.Equal(t, expected[0], hashes[0])
+					for i := 1; i < len(hashes); i++ {
+						require.Equal(t, hashes[0], hashes[i])
+					}
+				})
+			}
+		}
+	}
+}
+
+func TestComputeHashRootsWithInvalidParams(t *testing.T) {
+	cases := []struct {
+		name   string
+		params types.ComputeHashOptions
+	}{
+		{"zero segment size", types.ComputeHashOptions{DataShards: 4, ParityShards: 2}},
+		{"zero data shards", types.ComputeHashOptions{SegmentSize: 1024, ParityShards: 2}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, _, _, err := ComputeHashRootsWithParams(bytes.NewReader([]byte("payload")), storageTypes.REDUNDANCY_EC_TYPE, tc.params)
+			require.Error(t, err)
+		})
+	}
+	_, _, _, err := ComputeHashRootsWithParams(nil, storageTypes.REDUNDANCY_EC_TYPE,
+		types.ComputeHashOptions{SegmentSize: 1024, DataShards: 4, ParityShards: 2})
+	require.Error(t, err)
+}
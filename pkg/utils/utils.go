@@ -11,9 +11,12 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"path"
 	"strings"
 
 	"github.com/rs/zerolog/log"
+
+	"github.com/bnb-chain/greenfield-go-sdk/types"
 )
 
 var EmptyURL = url.URL{}
@@ -165,3 +168,70 @@ func GetContentLength(reader io.Reader) (int64, error) {
 	}
 	return contentLength, err
 }
+
+// contentTypes is the fixed table of the content types by the lower case extension, it does not depend on the
+// mime.types of the host so that the same object gets the same content type everywhere
+var contentTypes = map[string]string{
+	".7z":    "application/x-7z-compressed",
+	".avif":  "image/avif",
+	".bmp":   "image/bmp",
+	".css":   "text/css; charset=utf-8",
+	".csv":   "text/csv; charset=utf-8",
+	".doc":   "application/msword",
+	".docx":  "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+	".gif":   "image/gif",
+	".gz":    "application/gzip",
+	".htm":   "text/html; charset=utf-8",
+	".html":  "text/html; charset=utf-8",
+	".ico":   "image/x-icon",
+	".jpeg":  "image/jpeg",
+	".jpg":   "image/jpeg",
+	".js":    "text/javascript; charset=utf-8",
+	".json":  "application/json",
+	".md":    "text/markdown; charset=utf-8",
+	".mjs":   "text/javascript; charset=utf-8",
+	".mov":   "video/quicktime",
+	".mp3":   "audio/mpeg",
+	".mp4":   "video/mp4",
+	".ogg":   "audio/ogg",
+	".pdf":   "application/pdf",
+	".png":   "image/png",
+	".svg":   "image/svg+xml",
+	".tar":   "application/x-tar",
+	".tif":   "image/tiff",
+	".tiff":  "image/tiff",
+	".txt":   "text/plain; charset=utf-8",
+	".wasm":  "application/wasm",
+	".wav":   "audio/wav",
+	".webm":  "video/webm",
+	".webp":  "image/webp",
+	".woff":  "font/woff",
+	".woff2": "font/woff2",
+	".xls":   "application/vnd.ms-excel",
+	".xlsx":  "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	".xml":   "text/xml; charset=utf-8",
+	".yaml":  "application/yaml",
+	".yml":   "application/yaml",
+	".zip":   "application/zip",
+}
+
+// DetectContentType detects the content type of the object by the extension of the object name in the fixed table,
+// and sniffs the first 512 bytes of the payload by http.DetectContentType if the extension is unknown. It returns the
+// content type and the reader which replays the sniffed bytes, so that the payload is not consumed twice.
+func DetectContentType(objectName string, reader io.Reader) (string, io.Reader, error) {
+	if contentType, ok := contentTypes[strings.ToLower(path.Ext(objectName))]; ok {
+		return contentType, reader, nil
+	}
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(reader, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", nil, err
+	}
+	head = head[:n]
+	reader = io.MultiReader(bytes.NewReader(head), reader)
+	if n == 0 {
+		return types.ContentDefault, reader, nil
+	}
+	return http.DetectContentType(head), reader, nil
+}
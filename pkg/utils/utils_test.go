@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/greenfield-go-sdk/types"
+)
+
+type errReader struct{}
+
+func (errReader) Read([]byte) (int, error) {
+	return 0, errors.New("read failed")
+}
+
+func TestDetectContentType(t *testing.T) {
+	pngHeader := []byte("\x89PNG\x0D\x0A\x1A\x0A")
+	cases := []struct {
+		name        string
+		objectName  string
+		payload     []byte
+		contentType string
+	}{
+		{"known extension", "photos/a.jpg", []byte("not sniffed"), "image/jpeg"},
+		{"upper case extension", "photos/A.JPG", []byte("not sniffed"), "image/jpeg"},
+		{"extension wins over the payload", "data.json", pngHeader, "application/json"},
+		{"dot in the directory only", "dir.png/object", pngHeader, "image/png"},
+		{"unknown extension sniffs the payload", "object.unknown", pngHeader, "image/png"},
+		{"no extension sniffs text", "object", []byte("hello greenfield"), "text/plain; charset=utf-8"},
+		{"no extension sniffs binary", "object", []byte{0x00, 0x01, 0x02, 0x03}, "application/octet-stream"},
+		{"payload longer than the sniffed bytes", "object", append(pngHeader, bytes.Repeat([]byte{0x00}, 1024)...), "image/png"},
+		{"empty payload", "object", nil, types.ContentDefault},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			contentType, reader, err := DetectContentType(tc.objectName, bytes.NewReader(tc.payload))
+			require.NoError(t, err)
+			require.Equal(t, tc.contentType, contentType)
+
+			// the returned reader replays the whole payload
+			payload, err := io.ReadAll(reader)
+			require.NoError(t, err)
+			require.Equal(t, len(tc.payload), len(payload))
+			require.True(t, bytes.Equal(tc.payload, payload))
+		})
+	}
+
+	t.Run("read error", func(t *testing.T) {
+		_, _, err := DetectContentType("object", errReader{})
+		require.Error(t, err)
+	})
+
+	t.Run("known extension does not read the payload", func(t *testing.T) {
+		contentType, reader, err := DetectContentType("index.html", errReader{})
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(contentType, "text/html"))
+		require.Equal(t, errReader{}, reader)
+	})
+}
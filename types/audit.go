@@ -0,0 +1,76 @@
+package types
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// BucketAuditReport indicates the result of challenging the sampled pieces of the bucket
+type BucketAuditReport struct {
+	BucketName string
+	StartTime  time.Time
+	EndTime    time.Time
+	// SampledObjects indicates the names of the sealed objects which are challenged
+	SampledObjects []string
+	Challenges     int
+	Passed         int
+	// SPResults indicates the challenge results of each SP, the key is the SP operator address
+	SPResults map[string]*SPAuditResult
+}
+
+// SPAuditResult indicates the challenge results of one SP
+type SPAuditResult struct {
+	SPAddress  string
+	Challenges int
+	Passed     int
+	Failures   []AuditFailure
+}
+
+// AuditFailure indicates the challenged piece which failed to be verified
+type AuditFailure struct {
+	ObjectName      string
+	ObjectId        string
+	SPAddress       string
+	SegmentIndex    int
+	RedundancyIndex int
+	// Reason indicates the error of the challenge request or the failed check of the verdict
+	Reason string
+	// Verdict is nil if the SP failed to respond to the challenge request
+	Verdict *ChallengeVerdict
+	// ChallengeTxnHash indicates the txn hash of the challenge submitted on chain, it is empty if not submitted
+	ChallengeTxnHash string
+}
+
+// Failed returns the number of the failed challenges of all the SPs
+func (r *BucketAuditReport) Failed() int {
+	return r.Challenges - r.Passed
+}
+
+// String returns the readable summary of the audit
+func (r *BucketAuditReport) String() string {
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("audit bucket %s in %s: %d objects sampled, %d challenges, %d passed, %d failed\n",
+		r.BucketName, r.EndTime.Sub(r.StartTime).Round(time.Second), len(r.SampledObjects), r.Challenges, r.Passed, r.Failed()))
+
+	spAddrs := make([]string, 0, len(r.SPResults))
+	for spAddr := range r.SPResults {
+		spAddrs = append(spAddrs, spAddr)
+	}
+	sort.Strings(spAddrs)
+	for _, spAddr := range spAddrs {
+		result := r.SPResults[spAddr]
+		builder.WriteString(fmt.Sprintf("  SP %s: %d challenges, %d passed, %d failed\n", spAddr, result.Challenges,
+			result.Passed, len(result.Failures)))
+		for _, failure := range result.Failures {
+			builder.WriteString(fmt.Sprintf("    ! %s segment %d redundancy %d: %s", failure.ObjectName, failure.SegmentIndex,
+				failure.RedundancyIndex, failure.Reason))
+			if failure.ChallengeTxnHash != "" {
+				builder.WriteString(", challenged in txn " + failure.ChallengeTxnHash)
+			}
+			builder.WriteString("\n")
+		}
+	}
+	return strings.TrimSuffix(builder.String(), "\n")
+}
@@ -0,0 +1,67 @@
+package types
+
+import (
+	"fmt"
+	"time"
+
+	"cosmossdk.io/math"
+	challengetypes "github.com/bnb-chain/greenfield/x/challenge/types"
+)
+
+// ChallengeStatus indicates the progress of the submitted challenge
+type ChallengeStatus int
+
+const (
+	// ChallengePending indicates the challenge is waiting for the attestation
+	ChallengePending ChallengeStatus = iota
+	// ChallengeAttested indicates the challenge is attested by the validators
+	ChallengeAttested
+	// ChallengeExpired indicates the challenge is not attested before the expired height
+	ChallengeExpired
+)
+
+func (s ChallengeStatus) String() string {
+	switch s {
+	case ChallengePending:
+		return "pending"
+	case ChallengeAttested:
+		return "attested"
+	case ChallengeExpired:
+		return "expired"
+	default:
+		return "unknown"
+	}
+}
+
+// TrackedChallenge indicates the challenge submitted on chain, the fields are parsed from EventStartChallenge
+type TrackedChallenge struct {
+	ChallengeId       uint64
+	ObjectId          string
+	SegmentIndex      uint32
+	RedundancyIndex   int32
+	SPAddress         string
+	ChallengerAddress string
+	// ExpiredHeight indicates the challenge can not be attested after the block height
+	ExpiredHeight uint64
+	TxnHash       string
+	SubmitTime    time.Time
+	Status        ChallengeStatus
+}
+
+// String returns the readable summary of the challenge
+func (c TrackedChallenge) String() string {
+	return fmt.Sprintf("challenge %d of object %s segment %d redundancy %d on SP %s: %s", c.ChallengeId, c.ObjectId,
+		c.SegmentIndex, c.RedundancyIndex, c.SPAddress, c.Status)
+}
+
+// ChallengeAttestation indicates the vote result of the validators to attest the challenge
+type ChallengeAttestation struct {
+	SubmitterAddress  string
+	ChallengerAddress string
+	SPOperatorAddress string
+	ChallengeId       uint64
+	ObjectId          math.Uint
+	VoteResult        challengetypes.VoteResult
+	VoteValidatorSet  []uint64
+	VoteAggSignature  []byte
+}
@@ -2,6 +2,7 @@ package types
 
 import (
 	"runtime"
+	"time"
 )
 
 const (
@@ -47,4 +48,55 @@ const (
 	CreateBucketAction = "CreateBucket"
 
 	ChallengeUrl = "challenge"
+
+	// DefaultPermissionBatchSize is the default max number of policy msgs in one transaction when applying the permission plan
+	DefaultPermissionBatchSize = 10
+
+	// DefaultRebindBatchSize is the default max number of update bucket msgs in one transaction when rebinding buckets
+	DefaultRebindBatchSize = 10
+
+	// DefaultListGroupsLimit is the page size to list the groups from SP
+	DefaultListGroupsLimit = 1000
+
+	// DefaultWaitTxTimeout is the default max time to wait for the broadcast tx to be committed
+	DefaultWaitTxTimeout = 10 * time.Second
+	// DefaultSealTimeout is the default max time to wait for the object to be sealed
+	DefaultSealTimeout = 5 * time.Minute
+	// SealPollInterval is the interval to query the object status when waiting for the object to be sealed
+	SealPollInterval = 3 * time.Second
+
+	// SecondsPerDay and SecondsPerMonth are used to project the daily and monthly cost, a month is 30 days
+	SecondsPerDay   = 24 * 3600
+	SecondsPerMonth = 30 * SecondsPerDay
+	// PaymentRiskWindow indicates the payment account is at risk of freezing if it will be force settled within the window
+	PaymentRiskWindow = 7 * 24 * time.Hour
+	// DefaultPaymentCheckInterval is the default interval of the payment keeper to check the payment accounts
+	DefaultPaymentCheckInterval = 10 * time.Minute
+
+	// DefaultSPProbeTimeout is the default timeout to probe the endpoint of SP
+	DefaultSPProbeTimeout = 3 * time.Second
+	// SPLatencyCacheTTL is the time the probed latencies of SPs are reused by the latency selector
+	SPLatencyCacheTTL = time.Minute
+	// DefaultSPHealthCheckInterval is the default interval of the SP health checker to probe the SPs
+	DefaultSPHealthCheckInterval = 30 * time.Second
+	// DefaultSPHealthWindowSize is the default number of the recent probes kept for each SP
+	DefaultSPHealthWindowSize = 100
+	// DefaultSPFailureThreshold is the default number of consecutive failed probes to mark the SP as unhealthy
+	DefaultSPFailureThreshold = 3
+
+	// MaxStoragePriceHistory is the max number of prices returned by the price history query
+	MaxStoragePriceHistory = 1000
+	// DefaultPriceCheckInterval is the default interval of the price watcher to query the prices of the SPs
+	DefaultPriceCheckInterval = 10 * time.Minute
+
+	// DefaultAuditSampleSize is the default number of the objects sampled by the bucket audit
+	DefaultAuditSampleSize = 10
+
+	// DefaultChallengePollInterval is the default interval to query whether the tracked challenges are attested
+	DefaultChallengePollInterval = 5 * time.Second
+
+	// MaxMirrorSequenceScan is the max number of the sent packages scanned backward to find the mirror package
+	MaxMirrorSequenceScan = 1000
+	// DefaultMirrorPollInterval is the default interval to query whether the mirrored resource is acknowledged by BSC
+	DefaultMirrorPollInterval = 5 * time.Second
 )
@@ -0,0 +1,64 @@
+package types
+
+import (
+	"fmt"
+	"math/big"
+
+	"cosmossdk.io/math"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// CrossChainPackage indicates the cross-chain package decoded from the raw bytes stored on chain
+type CrossChainPackage struct {
+	ChannelId     uint32
+	Sequence      uint64
+	PackageType   sdk.CrossChainPackageType
+	Timestamp     uint64
+	RelayerFee    *big.Int
+	AckRelayerFee *big.Int
+	// OperationType indicates the operation of the package on the storage channels, it is 0 on the other channels
+	OperationType uint8
+	// Payload is the typed payload of the package, like *storagetypes.MirrorBucketSynPackage on the bucket channel
+	// and *bridgetypes.TransferOutSynPackage on the transfer out channel, it is nil if the ack package has no payload
+	Payload interface{}
+}
+
+// MirrorStatus indicates the progress of mirroring the resource to BSC
+type MirrorStatus int
+
+const (
+	// MirrorPending indicates the mirror package is sent and waiting for the ack from BSC
+	MirrorPending MirrorStatus = iota
+	// MirrorSucceeded indicates the resource is mirrored to BSC
+	MirrorSucceeded
+	// MirrorFailed indicates the mirror package is failed on BSC and the resource is not mirrored
+	MirrorFailed
+)
+
+func (s MirrorStatus) String() string {
+	switch s {
+	case MirrorPending:
+		return "pending"
+	case MirrorSucceeded:
+		return "succeeded"
+	case MirrorFailed:
+		return "failed"
+	default:
+		return "unknown"
+	}
+}
+
+// MirrorResult indicates the mirror package of the bucket, object or group and its outcome
+type MirrorResult struct {
+	ChannelId uint32
+	// Sequence indicates the send sequence of the mirror package on the channel
+	Sequence uint64
+	Id       math.Uint
+	Owner    string
+	Status   MirrorStatus
+}
+
+// String returns the readable summary of the mirror result
+func (r MirrorResult) String() string {
+	return fmt.Sprintf("mirror %s on channel %d sequence %d: %s", r.Id.String(), r.ChannelId, r.Sequence, r.Status)
+}
@@ -14,6 +14,13 @@ const unknownErr = "unknown error"
 var (
 	ErrorDefaultAccountNotExist = errors.New("Default account of client is not exist ")
 	ErrorProposalIDNotFound     = errors.New("Proposal ID not found ")
+	ErrorObjectIdentical        = errors.New("Object with the identical content already exists ")
+	ErrorPaymentAccountNotFound = errors.New("Payment account not found in the tx events ")
+	ErrorSPUnhealthy            = errors.New("Storage provider is unhealthy ")
+	ErrorChallengeIDNotFound    = errors.New("Challenge ID not found in the tx events ")
+	ErrorChallengeAttested      = errors.New("Challenge is already attested ")
+	ErrorNotInturnSubmitter     = errors.New("Not the in-turn attestation submitter ")
+	ErrorMirrorPackageNotFound  = errors.New("Mirror package not found in the sent packages ")
 )
 
 // ErrResponse define the information of the error response
@@ -0,0 +1,43 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+)
+
+// GroupMembersSyncReport indicates the member changes of the group made by the member synchronization
+type GroupMembersSyncReport struct {
+	GroupName  string
+	GroupOwner string
+	// DryRun indicates the changes are only planned and no transaction is sent
+	DryRun          bool
+	MembersToAdd    []string
+	MembersToRemove []string
+	// Unchanged indicates the number of the desired members which are already in the group
+	Unchanged int
+	// TxnHashes indicates the hashes of the UpdateGroupMember transactions
+	TxnHashes []string
+}
+
+// IsEmpty returns true if the members of the group are already in sync
+func (r *GroupMembersSyncReport) IsEmpty() bool {
+	return len(r.MembersToAdd) == 0 && len(r.MembersToRemove) == 0
+}
+
+// String returns the readable summary of the member changes, one line for each member
+func (r *GroupMembersSyncReport) String() string {
+	var builder strings.Builder
+	for _, member := range r.MembersToAdd {
+		builder.WriteString(fmt.Sprintf("+ %s\n", member))
+	}
+	for _, member := range r.MembersToRemove {
+		builder.WriteString(fmt.Sprintf("- %s\n", member))
+	}
+	verb := "synced"
+	if r.DryRun {
+		verb = "planned"
+	}
+	builder.WriteString(fmt.Sprintf("group %s %s: %d to add, %d to remove, %d unchanged.",
+		r.GroupName, verb, len(r.MembersToAdd), len(r.MembersToRemove), r.Unchanged))
+	return builder.String()
+}
@@ -97,6 +97,63 @@ type BucketMeta struct {
 	UpdateTime int64 `json:"update_time,string"`
 }
 
+type ListGroupsResult struct {
+	// groups defines the list of group of the owner
+	Groups []*storageType.GroupInfo
+	// next_key defines the pagination key of the next page, it is nil if there is no more group
+	NextKey []byte
+}
+
+type ListGroupMembersResult struct {
+	// Members defines the list of group member, the SP returns the members in the groups field
+	Members []*GroupMeta `json:"groups"`
+}
+
+type ListGroupsForMemberResult struct {
+	// groups defines the list of group which the member belongs to
+	Groups []*GroupMeta `json:"groups"`
+}
+
+// DuplicateObjects indicates the objects with the identical content in the bucket
+type DuplicateObjects struct {
+	// Checksum is the hex-encoded integrity hash of the primary SP
+	Checksum    string
+	PayloadSize uint64
+	ObjectNames []string
+}
+
+// GroupMeta is the structure for metadata service group member
+type GroupMeta struct {
+	// group defines the information of the group
+	Group *GroupInfo `json:"group"`
+	// account_id defines the address of the group member
+	AccountID string `json:"account_id"`
+	// operator defines the operator address of group member
+	Operator string `json:"operator"`
+	// create_at defines the block number when the member added
+	CreateAt int64 `json:"create_at,string"`
+	// create_time defines the timestamp when the member added
+	CreateTime int64 `json:"create_time,string"`
+	// update_at defines the block number when the member updated
+	UpdateAt int64 `json:"update_at,string"`
+	// update_time defines the timestamp when the member updated
+	UpdateTime int64 `json:"update_time,string"`
+	// removed defines the member is removed from the group or not, the removed members are kept in the result for pagination
+	Removed bool `json:"removed"`
+}
+
+// GroupInfo differ from GroupInfo in greenfield as it adds uint64/int64 unmarshal guide in json part
+type GroupInfo struct {
+	// owner is the owner of the group
+	Owner string `json:"owner"`
+	// group_name is the name of group which is unique under an account
+	GroupName string `json:"group_name"`
+	// source_type defines which chain the user should send the group management transactions to
+	SourceType storageType.SourceType `json:"source_type"`
+	// id is the unique identifier of group
+	Id storageType.Uint `json:"id"`
+}
+
 // ObjectInfo differ from ObjectInfo in greenfield as it adds uint64/int64 unmarshal guide in json part
 type ObjectInfo struct {
 	Owner string `json:"owner"`
@@ -0,0 +1,103 @@
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// BucketMigrationState indicates the progress of the bucket migration, it is saved to the state file after each step
+// so that the interrupted migration can be resumed
+type BucketMigrationState struct {
+	SrcBucketName       string `json:"src_bucket_name"`
+	DstBucketName       string `json:"dst_bucket_name"`
+	DstPrimarySPAddress string `json:"dst_primary_sp_address"`
+	BucketCreated       bool   `json:"bucket_created"`
+	// MigratedObjects indicates the objects which are sealed in the destination bucket and verified
+	MigratedObjects    map[string]bool `json:"migrated_objects"`
+	PoliciesReplicated bool            `json:"policies_replicated"`
+	SourceDeleted      bool            `json:"source_deleted"`
+}
+
+// LoadBucketMigrationState reads the migration state from the file, it returns nil if the file not exists
+func LoadBucketMigrationState(filePath string) (*BucketMigrationState, error) {
+	data, err := os.ReadFile(filePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	state := &BucketMigrationState{}
+	if err = json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("fail to parse the migration state file %s: %s", filePath, err.Error())
+	}
+	if state.MigratedObjects == nil {
+		state.MigratedObjects = make(map[string]bool)
+	}
+	return state, nil
+}
+
+// Save writes the migration state to the file, the file is replaced atomically
+func (s *BucketMigrationState) Save(filePath string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmpPath := filePath + ".tmp"
+	if err = os.WriteFile(tmpPath, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, filePath)
+}
+
+// BucketMigrationReport indicates the result of the bucket migration
+type BucketMigrationReport struct {
+	SrcBucketName       string
+	DstBucketName       string
+	SrcPrimarySPAddress string
+	DstPrimarySPAddress string
+	StartTime           time.Time
+	EndTime             time.Time
+	// Resumed indicates the migration is resumed from the state file
+	Resumed bool
+
+	// CopiedObjects indicates the objects copied in this run, CopiedBytes is their total payload size
+	CopiedObjects []string
+	CopiedBytes   uint64
+	// SkippedObjects indicates the objects which were already migrated
+	SkippedObjects []string
+	// FailedObjects indicates the objects failed to migrate with the reasons
+	FailedObjects map[string]string
+
+	// ReplicatedPolicies indicates the principals whose bucket policies are replicated
+	ReplicatedPolicies []string
+	SourceDeleted      bool
+}
+
+// String returns the readable summary of the migration
+func (r *BucketMigrationReport) String() string {
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("migrate bucket %s (sp %s) to %s (sp %s) in %s\n", r.SrcBucketName, r.SrcPrimarySPAddress,
+		r.DstBucketName, r.DstPrimarySPAddress, r.EndTime.Sub(r.StartTime).Round(time.Second)))
+	builder.WriteString(fmt.Sprintf("objects: %d copied (%d bytes), %d already migrated, %d failed\n",
+		len(r.CopiedObjects), r.CopiedBytes, len(r.SkippedObjects), len(r.FailedObjects)))
+
+	failed := make([]string, 0, len(r.FailedObjects))
+	for name := range r.FailedObjects {
+		failed = append(failed, name)
+	}
+	sort.Strings(failed)
+	for _, name := range failed {
+		builder.WriteString(fmt.Sprintf("  ! %s: %s\n", name, r.FailedObjects[name]))
+	}
+
+	builder.WriteString(fmt.Sprintf("policies: %d replicated\n", len(r.ReplicatedPolicies)))
+	builder.WriteString(fmt.Sprintf("source deleted: %t", r.SourceDeleted))
+	return builder.String()
+}
@@ -2,9 +2,12 @@ package types
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"cosmossdk.io/math"
+	"github.com/bnb-chain/greenfield-go-sdk/pkg/encryption"
 	gnfdsdktypes "github.com/bnb-chain/greenfield/sdk/types"
 	storageTypes "github.com/bnb-chain/greenfield/x/storage/types"
 	sdk "github.com/cosmos/cosmos-sdk/types"
@@ -51,6 +54,13 @@ type UpdatePaymentOption struct {
 	TxOpts *gnfdsdktypes.TxOption
 }
 
+// RebindBucketsOption indicates the options to move the buckets to another payment address
+// BatchSize indicates the max number of update msgs in one transaction, DefaultRebindBatchSize is used if it is not set
+type RebindBucketsOption struct {
+	TxOpts    *gnfdsdktypes.TxOption
+	BatchSize int
+}
+
 // UpdateBucketOption indicates the meta to construct updateBucket msg of storage module
 // PaymentAddress  indicates the HEX-encoded string of the payment address
 type UpdateBucketOption struct {
@@ -82,11 +92,129 @@ type DeleteGroupOption struct {
 
 // CreateObjectOptions indicates the metadata to construct `createObject` message of storage module
 type CreateObjectOptions struct {
-	Visibility      storageTypes.VisibilityType
-	TxOpts          *gnfdsdktypes.TxOption
+	Visibility storageTypes.VisibilityType
+	TxOpts     *gnfdsdktypes.TxOption
+	// SecondarySPAccs indicates the expected secondary SPs, they are chosen by the SP selector of the client if it is empty
 	SecondarySPAccs []sdk.AccAddress
 	ContentType     string
 	IsReplicaType   bool // indicates whether the object use REDUNDANCY_REPLICA_TYPE
+	// HashOptions indicates the redundancy params to compute the hash roots, the params on chain are queried if it is nil.
+	// It should be consistent with the redundancy params on chain.
+	HashOptions *ComputeHashOptions
+	// SkipIfIdentical indicates skipping the creation and returning ErrorObjectIdentical if the object already exists
+	// with the same checksums as the payload
+	SkipIfIdentical bool
+	// Compression indicates the name of the codec to compress the payload before computing the hash roots,
+	// the codec and the logical size are recorded in the parameters of the content type
+	Compression string
+}
+
+// CopyObjectOptions indicates the options to copy the object to another bucket, the content type and the redundancy type
+// of the source object are kept
+type CopyObjectOptions struct {
+	// Visibility indicates the visibility of the destination object, the visibility of the source object is kept if it is unspecified
+	Visibility      storageTypes.VisibilityType
+	TxOpts          *gnfdsdktypes.TxOption
+	SecondarySPAccs []sdk.AccAddress
+	// SourceHashOptions indicates the redundancy params used to create the source object. The checksums of the source object
+	// are reused if the params are the same as the params on chain, the checksums are computed by streaming the source
+	// object once more otherwise. The segment size is not recorded on chain, so the checksums are always recomputed if it is nil.
+	SourceHashOptions *ComputeHashOptions
+	// Progress and RateLimit are applied to the payload streamed from the source SP to the destination SP
+	Progress  ProgressFunc
+	RateLimit int64
+	// SealTimeout indicates the max time to wait for the destination object to be sealed, it is used by MoveObject,
+	// DefaultSealTimeout is used if it is zero
+	SealTimeout time.Duration
+}
+
+// MigrateBucketOptions indicates the options of the bucket migration
+type MigrateBucketOptions struct {
+	// PolicyPrincipals indicates the accounts and groups whose bucket policies are replicated to the new bucket,
+	// the policies can not be listed on chain so the principals should be given
+	PolicyPrincipals PolicyPrincipal
+	// DeleteSource indicates deleting the objects and the old bucket after all the objects are migrated and verified
+	// by the checksums, the deletion is refused if any object is encrypted on client side
+	DeleteSource bool
+	// StateFile indicates the file to save the migration state, the migration is resumed from it if the file exists
+	StateFile string
+	// CopyOptions indicates the options to copy each object, the visibility of the source objects is always kept
+	CopyOptions CopyObjectOptions
+}
+
+// EstimateCostOptions indicates the bucket and the planned uploads to estimate the storage cost
+type EstimateCostOptions struct {
+	// BucketName indicates the existing bucket whose current billing size is included in the estimate
+	BucketName string
+	// PrimarySPAddress indicates the HEX-encoded string of the primary SP address of the planned bucket,
+	// it is ignored if BucketName is set
+	PrimarySPAddress string
+	// ObjectSizes indicates the payload sizes of the planned uploads
+	ObjectSizes []uint64
+	// ChargedReadQuota indicates the read quota of the planned bucket, it overrides the read quota of the existing
+	// bucket if it is positive
+	ChargedReadQuota uint64
+}
+
+// PaymentKeeperOptions indicates the payment accounts watched by the payment keeper and the top-up policy,
+// the deposits are paid by the default account of the client
+type PaymentKeeperOptions struct {
+	// Accounts indicates the HEX-encoded string list of the payment account addresses to watch
+	Accounts []string
+	// MinRunway indicates the account is topped up if it will be force settled within the runway
+	MinRunway time.Duration
+	// TargetRunway indicates the runway to reach by the top-up, it should be longer than MinRunway
+	TargetRunway time.Duration
+	// MaxDepositPerDay indicates the max amount deposited to all the accounts in any 24 hours, it is not limited if it is nil
+	MaxDepositPerDay *math.Int
+	// CheckInterval indicates the interval to check the accounts, DefaultPaymentCheckInterval is used if it is zero
+	CheckInterval time.Duration
+	TxOpts        *gnfdsdktypes.TxOption
+	// OnTopUp is called for each top-up attempt, including the failed and the capped ones
+	OnTopUp func(event TopUpEvent)
+}
+
+// SPHealthCheckOptions indicates how the SP health checker probes the SPs
+type SPHealthCheckOptions struct {
+	// CheckInterval indicates the interval to probe the SPs, DefaultSPHealthCheckInterval is used if it is zero
+	CheckInterval time.Duration
+	// ProbeTimeout indicates the timeout of each probe, DefaultSPProbeTimeout is used if it is zero
+	ProbeTimeout time.Duration
+	// WindowSize indicates the number of the recent probes used to compute the availability and the latency percentiles,
+	// DefaultSPHealthWindowSize is used if it is zero
+	WindowSize int
+	// FailureThreshold indicates the SP is unhealthy after the number of consecutive failed probes,
+	// DefaultSPFailureThreshold is used if it is zero
+	FailureThreshold int
+}
+
+// StoragePriceWatchOptions indicates the SPs watched by the price watcher
+type StoragePriceWatchOptions struct {
+	// SPs indicates the HEX-encoded string list of the SP operator addresses to watch
+	SPs []string
+	// CheckInterval indicates the interval to query the prices, DefaultPriceCheckInterval is used if it is zero
+	CheckInterval time.Duration
+	// OnChange is called for each change of the read price or the store price
+	OnChange func(change StoragePriceChange)
+}
+
+// AuditBucketOptions indicates how the pieces of the bucket are sampled and challenged
+type AuditBucketOptions struct {
+	// SampleSize indicates the number of the sealed objects to sample, DefaultAuditSampleSize is used if it is zero
+	SampleSize int
+	// ChallengesPerObject indicates the number of the random redundancy indexes challenged for each sampled object,
+	// every SP storing the object is challenged if it is zero
+	ChallengesPerObject int
+	// SubmitChallenge indicates submitting the on-chain challenges for the pieces whose verification failed,
+	// the challenges are submitted by the default account of the client
+	SubmitChallenge bool
+	TxOpts          *gnfdsdktypes.TxOption
+}
+
+// ChallengeTrackerOptions indicates how the challenge tracker polls the attestation of the challenges
+type ChallengeTrackerOptions struct {
+	// PollInterval indicates the interval to query the attested challenges, DefaultChallengePollInterval is used if it is zero
+	PollInterval time.Duration
 }
 
 // CreateGroupOptions  indicates the meta to construct createGroup msg
@@ -105,10 +233,12 @@ type LeaveGroupOption struct {
 }
 
 // ComputeHashOptions indicates the metadata of redundancy strategy
+// Parallelism indicates the max number of the segments hashed concurrently, the number of CPUs is used if it is not positive
 type ComputeHashOptions struct {
 	SegmentSize  uint64
 	DataShards   uint32
 	ParityShards uint32
+	Parallelism  int
 }
 
 // ListReadRecordOptions indicates the start timestamp of the return read quota record
@@ -121,6 +251,37 @@ type ListObjectsOptions struct {
 	ShowRemovedObject bool
 }
 
+// SyncGroupMembersOptions indicates the options to sync the group members
+// DryRun indicates only planning the member changes without sending transactions
+// BatchSize indicates the max number of member changes in one UpdateGroupMember transaction,
+// it is limited by the max number of members to update once on chain
+type SyncGroupMembersOptions struct {
+	DryRun    bool
+	BatchSize int
+	TxOpts    *gnfdsdktypes.TxOption
+}
+
+// ListGroupsOptions indicates the pagination of listing the groups of the owner on chain
+// PaginationKey indicates the NextKey returned by the previous page, Limit indicates the max number of groups to return
+type ListGroupsOptions struct {
+	PaginationKey []byte
+	Limit         uint64
+}
+
+// ListGroupMembersOptions indicates the pagination of listing the group members from SP
+// StartAfter indicates the member address to start after, Limit indicates the max number of members to return
+type ListGroupMembersOptions struct {
+	StartAfter string
+	Limit      int64
+}
+
+// ListGroupsForMemberOptions indicates the pagination of listing the groups which the member belongs to from SP
+// StartAfter indicates the group id to start after, Limit indicates the max number of groups to return
+type ListGroupsForMemberOptions struct {
+	StartAfter string
+	Limit      int64
+}
+
 type PutPolicyOption struct {
 	TxOpts           *gnfdsdktypes.TxOption
 	PolicyExpireTime *time.Time
@@ -130,6 +291,27 @@ type DeletePolicyOption struct {
 	TxOpts *gnfdsdktypes.TxOption
 }
 
+// ApplyPlanOption indicates the options to execute the permission plan
+// BatchSize indicates the max number of policy msgs in one transaction, DefaultPermissionBatchSize is used if it is not set
+type ApplyPlanOption struct {
+	TxOpts    *gnfdsdktypes.TxOption
+	BatchSize int
+}
+
+// ExplainPermissionOptions indicates the options to explain the permission
+// Groups indicates the groups to check the membership and the group policies, if it is empty,
+// the groups which the user belongs to are listed from SP
+// WantedSize indicates the size of the object to create, it is used to check the limit size of the CreateObject statements
+type ExplainPermissionOptions struct {
+	Groups     []GroupIdentity
+	WantedSize uint64
+}
+
+// ApplyPolicyOption indicates the txn options of the put policy transactions generated from the policy document
+type ApplyPolicyOption struct {
+	TxOpts *gnfdsdktypes.TxOption
+}
+
 type NewStatementOptions struct {
 	StatementExpireTime *time.Time
 	LimitSize           uint64
@@ -148,11 +330,37 @@ type ApproveObjectOptions struct {
 type PutObjectOptions struct {
 	ContentType string
 	TxnHash     string
+	// Compression indicates the name of the codec to compress the payload, it should be the same as the one
+	// used to create the object. The objectSize of PutObject is ignored and the stored size on chain is used.
+	Compression string
+	// Progress is called with the bytes uploaded and the total bytes while uploading the payload
+	Progress ProgressFunc
+	// RateLimit indicates the max bytes uploaded per second of this call, it is not limited if it is zero,
+	// the client-wide rate limit is applied as well
+	RateLimit int64
+}
+
+// ProgressFunc reports the bytes transferred and the total bytes of the payload, the total is -1 if it is unknown
+type ProgressFunc func(transferred, total int64)
+
+// EncryptionOptions indicates the options of the client-side encryption
+// ChunkSize indicates the plaintext size of one encrypted chunk, encryption.DefaultChunkSize is used if it is zero
+type EncryptionOptions struct {
+	KeyProvider encryption.KeyProvider
+	ChunkSize   uint32
 }
 
 // GetObjectOption contains the options of getObject
 type GetObjectOption struct {
 	Range string `url:"-" header:"Range,omitempty"` // support for downloading partial data
+	// SkipDecompression indicates returning the stored payload of the compressed object without decompression,
+	// the payload is not decompressed for the range request either
+	SkipDecompression bool `url:"-" header:"-"`
+	// Progress is called with the bytes downloaded and the total bytes while reading the returned payload
+	Progress ProgressFunc `url:"-" header:"-"`
+	// RateLimit indicates the max bytes downloaded per second of this call, it is not limited if it is zero,
+	// the client-wide rate limit is applied as well
+	RateLimit int64 `url:"-" header:"-"`
 }
 
 func (o *GetObjectOption) SetRange(start, end int64) error {
@@ -171,3 +379,28 @@ func (o *GetObjectOption) SetRange(start, end int64) error {
 	}
 	return nil
 }
+
+// GetRange parses the range set by SetRange, it returns -1 as the start if the range is not set,
+// and returns -1 as the end if the range is open-ended
+func (o *GetObjectOption) GetRange() (int64, int64, error) {
+	if o.Range == "" {
+		return -1, -1, nil
+	}
+
+	bounds := strings.SplitN(strings.TrimPrefix(o.Range, "bytes="), "-", 2)
+	if !strings.HasPrefix(o.Range, "bytes=") || len(bounds) != 2 {
+		return 0, 0, ToInvalidArgumentResp(fmt.Sprintf("Invalid Range : %s", o.Range))
+	}
+	start, err := strconv.ParseInt(bounds[0], 10, 64)
+	if err != nil || start < 0 {
+		return 0, 0, ToInvalidArgumentResp(fmt.Sprintf("Invalid Range : %s", o.Range))
+	}
+	if bounds[1] == "" {
+		return start, -1, nil
+	}
+	end, err := strconv.ParseInt(bounds[1], 10, 64)
+	if err != nil || end < start {
+		return 0, 0, ToInvalidArgumentResp(fmt.Sprintf("Invalid Range : %s", o.Range))
+	}
+	return start, end, nil
+}
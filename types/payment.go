@@ -0,0 +1,139 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"cosmossdk.io/math"
+	paymentTypes "github.com/bnb-chain/greenfield/x/payment/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// CostEstimate indicates the projected storage cost of the bucket, the rates are in wei of BNB per second
+type CostEstimate struct {
+	PrimarySPAddress string
+	// ChargeSize indicates the total size charged by the primary SP, each object is charged at least the min charge size
+	ChargeSize uint64
+	// SecondaryChargeSize indicates the total size charged by all the secondary SPs, every secondary SP stores one
+	// redundancy copy of the objects, so it is the charge size multiplied by the data and parity shards
+	SecondaryChargeSize uint64
+	ChargedReadQuota    uint64
+
+	ReadPrice           sdk.Dec
+	PrimaryStorePrice   sdk.Dec
+	SecondaryStorePrice sdk.Dec
+
+	ReadRate           math.Int
+	PrimaryStoreRate   math.Int
+	SecondaryStoreRate math.Int
+	ValidatorTaxRate   math.Int
+	TotalRate          math.Int
+	// MonthlyCost indicates the total cost of SecondsPerMonth seconds at the total rate
+	MonthlyCost math.Int
+	// UploadLockFee indicates the fee locked in the payment account when creating the planned objects,
+	// it is unlocked when the objects are sealed
+	UploadLockFee math.Int
+}
+
+// String returns the readable summary of the estimate in BNB
+func (e *CostEstimate) String() string {
+	month := math.NewInt(SecondsPerMonth)
+	return fmt.Sprintf("monthly cost: %s BNB (read %s, primary store %s, secondary store %s, validator tax %s), upload lock fee: %s BNB",
+		WeiToBNB(e.MonthlyCost), WeiToBNB(e.ReadRate.Mul(month)), WeiToBNB(e.PrimaryStoreRate.Mul(month)),
+		WeiToBNB(e.SecondaryStoreRate.Mul(month)), WeiToBNB(e.ValidatorTaxRate.Mul(month)), WeiToBNB(e.UploadLockFee))
+}
+
+// WeiToBNB returns the decimal string of the amount in BNB
+func WeiToBNB(amount math.Int) string {
+	if amount.IsNil() {
+		return "0"
+	}
+	return sdk.NewDecFromIntWithPrec(amount, 18).String()
+}
+
+// BucketOutflow indicates the flow rate of the bucket paid by the payment account, in wei of BNB per second
+type BucketOutflow struct {
+	BucketName string
+	Rate       math.Int
+}
+
+// PaymentHealth indicates the balance and the runway of the payment account at the block time
+type PaymentHealth struct {
+	Account   string
+	Status    paymentTypes.StreamAccountStatus
+	BlockTime time.Time
+
+	StaticBalance math.Int
+	BufferBalance math.Int
+	LockBalance   math.Int
+	// NetflowRate is negative if the account pays more than it receives
+	NetflowRate math.Int
+	// DynamicBalance indicates the static balance settled to the block time
+	DynamicBalance math.Int
+	// BurnRatePerDay indicates the net outflow of one day, it is zero if the account is not paying
+	BurnRatePerDay math.Int
+
+	// DepletionTime indicates when the dynamic balance and the buffer balance run out,
+	// SettleTime indicates when the account will be force settled and frozen, they are nil if the account is not paying.
+	// DepletionTime is also nil if the balance lasts beyond the range of the unix time.
+	DepletionTime *time.Time
+	SettleTime    *time.Time
+	// AtRisk indicates the account is frozen or will be frozen within the PaymentRiskWindow
+	AtRisk bool
+
+	// OutFlows indicates the flows to the SPs and the validator tax pool
+	OutFlows []paymentTypes.OutFlow
+	// Buckets indicates the buckets paid by the account, sorted by the rate in descending order
+	Buckets []BucketOutflow
+}
+
+// String returns the readable summary of the payment health
+func (h *PaymentHealth) String() string {
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("payment account %s is %s at %s\n", h.Account, h.Status.String(), h.BlockTime.UTC().Format(time.RFC3339)))
+	builder.WriteString(fmt.Sprintf("balance: %s BNB (buffer %s BNB, locked %s BNB)\n", WeiToBNB(h.DynamicBalance),
+		WeiToBNB(h.BufferBalance), WeiToBNB(h.LockBalance)))
+	builder.WriteString(fmt.Sprintf("burn rate: %s BNB/day\n", WeiToBNB(h.BurnRatePerDay)))
+	if h.SettleTime != nil {
+		builder.WriteString(fmt.Sprintf("frozen at: %s (runway %s)\n", h.SettleTime.UTC().Format(time.RFC3339),
+			h.SettleTime.Sub(h.BlockTime).Round(time.Minute)))
+	}
+	for _, bucket := range h.Buckets {
+		builder.WriteString(fmt.Sprintf("  %s: %s BNB/day\n", bucket.BucketName, WeiToBNB(bucket.Rate.MulRaw(SecondsPerDay))))
+	}
+	builder.WriteString(fmt.Sprintf("at risk: %t", h.AtRisk))
+	return builder.String()
+}
+
+// TopUpEvent indicates one top-up attempt of the payment keeper
+type TopUpEvent struct {
+	Account string
+	Time    time.Time
+	// Runway indicates the time to the force settlement before the top-up
+	Runway time.Duration
+	// Frozen indicates the account is frozen before the top-up, Minimum indicates the amount to resume it,
+	// which covers the reserve of the out flows and the debt of the static balance
+	Frozen  bool
+	Minimum math.Int
+	// Required indicates the amount to reach the target runway, Amount indicates the amount deposited
+	Required math.Int
+	Amount   math.Int
+	// Capped indicates the amount is reduced or skipped by the daily spending cap
+	Capped  bool
+	TxnHash string
+	Err     error
+}
+
+// PaymentKeeperMetrics indicates the statistics of the payment keeper since it starts
+type PaymentKeeperMetrics struct {
+	Checks         uint64
+	TopUps         uint64
+	FailedTopUps   uint64
+	CappedTopUps   uint64
+	TotalDeposited math.Int
+	// DepositedLastDay indicates the amount deposited in the last 24 hours which counts toward the spending cap,
+	// including the deposits being broadcast
+	DepositedLastDay math.Int
+	LastCheckTime    time.Time
+}
@@ -0,0 +1,207 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	permTypes "github.com/bnb-chain/greenfield/x/permission/types"
+	storageTypes "github.com/bnb-chain/greenfield/x/storage/types"
+)
+
+type PermissionChangeType string
+
+const (
+	PermissionChangeAdd    PermissionChangeType = "add"
+	PermissionChangeUpdate PermissionChangeType = "change"
+	PermissionChangeRevoke PermissionChangeType = "revoke"
+)
+
+// PermissionState indicates the desired permission state used to plan the permission changes.
+// The managed scope of the reconciliation is all the resources and principals that appear in the Document,
+// plus the extra Resources and Principals. The policies of the managed principals on the managed resources
+// which are not declared in the Document will be revoked.
+type PermissionState struct {
+	Document *PolicyDocument
+	// Resources indicates the extra GRN of the resources to be managed, such as "grn:b::bucket"
+	Resources []string
+	// Principals indicates the extra accounts and groups to be managed
+	Principals PolicyPrincipal
+}
+
+// PermissionChange indicates the change of the policy of one principal on one resource
+type PermissionChange struct {
+	Type      PermissionChangeType
+	Resource  string
+	Principal *permTypes.Principal
+	// Current indicates the policy on chain, it is nil if the change type is add
+	Current *permTypes.Policy
+	// Statements and ExpirationTime indicate the desired policy, they are empty if the change type is revoke
+	Statements     []*permTypes.Statement
+	ExpirationTime *time.Time
+}
+
+// String returns the readable description of the change
+func (c PermissionChange) String() string {
+	var symbol string
+	switch c.Type {
+	case PermissionChangeAdd:
+		symbol = "+"
+	case PermissionChangeUpdate:
+		symbol = "~"
+	default:
+		symbol = "-"
+	}
+
+	statements := c.Statements
+	if c.Type == PermissionChangeRevoke && c.Current != nil {
+		statements = c.Current.Statements
+	}
+
+	descs := make([]string, 0, len(statements))
+	for _, s := range statements {
+		actions := make([]string, 0, len(s.Actions))
+		for _, action := range s.Actions {
+			actions = append(actions, PolicyActionName(action))
+		}
+		desc := fmt.Sprintf("%s %s", strings.TrimPrefix(s.Effect.String(), "EFFECT_"), strings.Join(actions, ","))
+		if len(s.Resources) > 0 {
+			desc += fmt.Sprintf(" on %s", strings.Join(s.Resources, ","))
+		}
+		descs = append(descs, desc)
+	}
+
+	return fmt.Sprintf("%s %s %s to %s: [%s]", symbol, c.Type, c.Resource, principalString(c.Principal), strings.Join(descs, "; "))
+}
+
+// PermissionPlan indicates the permission changes to apply to reach the desired state
+type PermissionPlan struct {
+	Changes []PermissionChange
+	// Unchanged indicates the number of the managed policies which are already in the desired state
+	Unchanged int
+}
+
+// IsEmpty returns true if there is nothing to change
+func (p *PermissionPlan) IsEmpty() bool {
+	return len(p.Changes) == 0
+}
+
+// Count returns the number of the changes of the change type
+func (p *PermissionPlan) Count(changeType PermissionChangeType) int {
+	count := 0
+	for _, c := range p.Changes {
+		if c.Type == changeType {
+			count++
+		}
+	}
+	return count
+}
+
+// String returns the readable summary of the plan, one line for each change
+func (p *PermissionPlan) String() string {
+	var builder strings.Builder
+	for _, c := range p.Changes {
+		builder.WriteString(c.String())
+		builder.WriteString("\n")
+	}
+	builder.WriteString(fmt.Sprintf("Plan: %d to add, %d to change, %d to revoke, %d unchanged.",
+		p.Count(PermissionChangeAdd), p.Count(PermissionChangeUpdate), p.Count(PermissionChangeRevoke), p.Unchanged))
+	return builder.String()
+}
+
+func principalString(principal *permTypes.Principal) string {
+	if principal == nil {
+		return ""
+	}
+	if principal.Type == permTypes.PRINCIPAL_TYPE_GNFD_GROUP {
+		return "group " + principal.Value
+	}
+	return "account " + principal.Value
+}
+
+// GroupIdentity indicates the group by the group owner and group name
+// GroupOwner indicates the HEX-encoded string of the group owner address
+type GroupIdentity struct {
+	GroupOwner string
+	GroupName  string
+}
+
+// GroupMembership indicates whether the user is a member of the group
+type GroupMembership struct {
+	GroupIdentity
+	GroupId  uint64
+	IsMember bool
+}
+
+// StatementEvaluation indicates the evaluation result of one statement of the policy
+// Result is EFFECT_UNSPECIFIED if the statement is expired or does not match the action and resource
+type StatementEvaluation struct {
+	Index     int
+	Statement *permTypes.Statement
+	Expired   bool
+	Result    permTypes.Effect
+}
+
+// PolicyEvaluation indicates the evaluation result of the policy of one principal on one resource
+type PolicyEvaluation struct {
+	Resource  string
+	Principal *permTypes.Principal
+	// Group is set if the principal is a group
+	Group *GroupMembership
+	// Found indicates whether the policy exists on chain
+	Found      bool
+	PolicyId   string
+	Expired    bool
+	Statements []StatementEvaluation
+	// Effect is the evaluation result of the policy, DecisiveStatement is the index of the statement
+	// which decided the effect, it is -1 if no statement matched
+	Effect            permTypes.Effect
+	DecisiveStatement int
+}
+
+// PermissionExplanation indicates the report of why the action is allowed or denied to the user
+type PermissionExplanation struct {
+	User       string
+	BucketName string
+	ObjectName string
+	Action     permTypes.ActionType
+	// BlockTime indicates the latest block time which is used to check the expiration of the policies
+	BlockTime time.Time
+
+	Owner            string
+	IsOwner          bool
+	BucketVisibility storageTypes.VisibilityType
+	ObjectVisibility storageTypes.VisibilityType
+	// IsPublicRead indicates whether the action is allowed to everyone by the visibility
+	IsPublicRead bool
+
+	Groups   []GroupMembership
+	Policies []PolicyEvaluation
+
+	// Effect is the result of the explanation and DecidedBy describes the rule which decided it
+	Effect    permTypes.Effect
+	DecidedBy string
+	// ChainEffect is the result of the permission verification on chain
+	ChainEffect permTypes.Effect
+	// Steps describes the evaluation steps in order
+	Steps []string
+}
+
+// String returns the readable report of the explanation
+func (e *PermissionExplanation) String() string {
+	var builder strings.Builder
+	resource := e.BucketName
+	if e.ObjectName != "" {
+		resource += "/" + e.ObjectName
+	}
+	builder.WriteString(fmt.Sprintf("%s of %s on %s: %s\n", PolicyActionName(e.Action), e.User, resource, e.Effect.String()))
+	builder.WriteString(fmt.Sprintf("decided by: %s\n", e.DecidedBy))
+	for i, step := range e.Steps {
+		builder.WriteString(fmt.Sprintf("  %d. %s\n", i+1, step))
+	}
+	if e.ChainEffect != e.Effect {
+		builder.WriteString(fmt.Sprintf("the chain verification result is %s, the user may be a member of groups which are not checked\n",
+			e.ChainEffect.String()))
+	}
+	return strings.TrimSuffix(builder.String(), "\n")
+}
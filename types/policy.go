@@ -0,0 +1,340 @@
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	gnfdTypes "github.com/bnb-chain/greenfield/types"
+	permTypes "github.com/bnb-chain/greenfield/x/permission/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+const (
+	PolicyDocumentVersion = "2023-05-01"
+
+	PolicyEffectAllow = "Allow"
+	PolicyEffectDeny  = "Deny"
+
+	// PolicyActionPrefix is the optional prefix of the action names in a policy document, such as "gnfd:GetObject"
+	PolicyActionPrefix = "gnfd:"
+)
+
+// policyActions maps the action names used in policy documents to the action types of permission module
+var policyActions = map[string]permTypes.ActionType{
+	"UpdateBucketInfo":  permTypes.ACTION_UPDATE_BUCKET_INFO,
+	"DeleteBucket":      permTypes.ACTION_DELETE_BUCKET,
+	"CreateObject":      permTypes.ACTION_CREATE_OBJECT,
+	"DeleteObject":      permTypes.ACTION_DELETE_OBJECT,
+	"CopyObject":        permTypes.ACTION_COPY_OBJECT,
+	"GetObject":         permTypes.ACTION_GET_OBJECT,
+	"ExecuteObject":     permTypes.ACTION_EXECUTE_OBJECT,
+	"ListObject":        permTypes.ACTION_LIST_OBJECT,
+	"UpdateGroupMember": permTypes.ACTION_UPDATE_GROUP_MEMBER,
+	"DeleteGroup":       permTypes.ACTION_DELETE_GROUP,
+	"UpdateObjectInfo":  permTypes.ACTION_UPDATE_OBJECT_INFO,
+	"*":                 permTypes.ACTION_TYPE_ALL,
+}
+
+// PolicyDocument is a JSON policy document in the style of S3 bucket policies.
+// Each statement grants or denies the actions on the resources to the principals,
+// the document is translated into the put policy transactions of bucket, object or group.
+type PolicyDocument struct {
+	Version   string            `json:"Version,omitempty"`
+	Statement []PolicyStatement `json:"Statement"`
+	// ExpirationTime defines the expiration time of the whole policy, it takes priority over the statement expiration time
+	ExpirationTime *time.Time `json:"ExpirationTime,omitempty"`
+}
+
+// PolicyStatement is a statement of the policy document
+// Action indicates the action names such as "gnfd:GetObject" or "*"
+// Resource indicates the GRN of the resources, such as "grn:b::bucket", "grn:o::bucket/photos/*" or "grn:g:owner/group",
+// the object name can contain the wildcards "*" and "?" which match the whole object name like a shell glob,
+// they are granted as the sub-resources of the bucket
+type PolicyStatement struct {
+	Sid            string          `json:"Sid,omitempty"`
+	Effect         string          `json:"Effect"`
+	Principal      PolicyPrincipal `json:"Principal"`
+	Action         []string        `json:"Action"`
+	Resource       []string        `json:"Resource"`
+	ExpirationTime *time.Time      `json:"ExpirationTime,omitempty"`
+	LimitSize      uint64          `json:"LimitSize,omitempty"`
+}
+
+// PolicyPrincipal indicates the accounts and groups that the statement applies to
+// Account indicates the HEX-encoded string list of the account addresses
+type PolicyPrincipal struct {
+	Account []string `json:"Account,omitempty"`
+	Group   []uint64 `json:"Group,omitempty"`
+}
+
+// ParsePolicyDocument decodes the JSON policy document and validates it
+func ParsePolicyDocument(data []byte) (*PolicyDocument, error) {
+	doc := &PolicyDocument{}
+	if err := json.Unmarshal(data, doc); err != nil {
+		return nil, err
+	}
+
+	if err := doc.Validate(); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// JSON returns the indented JSON encoding of the policy document
+func (d *PolicyDocument) JSON() ([]byte, error) {
+	return json.MarshalIndent(d, "", "  ")
+}
+
+// Validate checks the effect, actions, principals and resources of all the statements
+func (d *PolicyDocument) Validate() error {
+	if len(d.Statement) == 0 {
+		return errors.New("policy document has no statement")
+	}
+
+	for i := range d.Statement {
+		if err := d.Statement[i].Validate(); err != nil {
+			return fmt.Errorf("invalid statement %d: %s", i, err.Error())
+		}
+	}
+	return nil
+}
+
+// Validate checks the effect, actions, principals and resources of the statement
+func (s *PolicyStatement) Validate() error {
+	if _, err := s.PermEffect(); err != nil {
+		return err
+	}
+
+	if _, err := s.PermActions(); err != nil {
+		return err
+	}
+
+	if len(s.Principal.Account) == 0 && len(s.Principal.Group) == 0 {
+		return errors.New("no principal specified")
+	}
+
+	for _, addr := range s.Principal.Account {
+		if _, err := sdk.AccAddressFromHexUnsafe(addr); err != nil {
+			return fmt.Errorf("invalid principal account %s: %s", addr, err.Error())
+		}
+	}
+
+	for _, groupId := range s.Principal.Group {
+		if groupId == 0 {
+			return errors.New("invalid principal group id 0")
+		}
+	}
+
+	if len(s.Resource) == 0 {
+		return errors.New("no resource specified")
+	}
+
+	for _, res := range s.Resource {
+		var grn gnfdTypes.GRN
+		if err := grn.ParseFromString(res, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PermEffect returns the effect of permission module according to the statement effect
+func (s *PolicyStatement) PermEffect() (permTypes.Effect, error) {
+	switch {
+	case strings.EqualFold(s.Effect, PolicyEffectAllow):
+		return permTypes.EFFECT_ALLOW, nil
+	case strings.EqualFold(s.Effect, PolicyEffectDeny):
+		return permTypes.EFFECT_DENY, nil
+	default:
+		return permTypes.EFFECT_UNSPECIFIED, fmt.Errorf("invalid effect %q, should be %s or %s", s.Effect, PolicyEffectAllow, PolicyEffectDeny)
+	}
+}
+
+// PermActions returns the action types of permission module according to the statement actions
+func (s *PolicyStatement) PermActions() ([]permTypes.ActionType, error) {
+	if len(s.Action) == 0 {
+		return nil, errors.New("no action specified")
+	}
+
+	actions := make([]permTypes.ActionType, 0, len(s.Action))
+	for _, name := range s.Action {
+		action, err := ParsePolicyAction(name)
+		if err != nil {
+			return nil, err
+		}
+		actions = append(actions, action)
+	}
+	return actions, nil
+}
+
+// ParsePolicyAction returns the action type of the action name, the name can be "GetObject",
+// "gnfd:GetObject" or the enum name "ACTION_GET_OBJECT"
+func ParsePolicyAction(name string) (permTypes.ActionType, error) {
+	if action, ok := policyActions[strings.TrimPrefix(name, PolicyActionPrefix)]; ok {
+		return action, nil
+	}
+
+	if action, ok := permTypes.ActionType_value[name]; ok && action != int32(permTypes.ACTION_UNSPECIFIED) {
+		return permTypes.ActionType(action), nil
+	}
+
+	return permTypes.ACTION_UNSPECIFIED, fmt.Errorf("unknown action %q", name)
+}
+
+// PolicyActionName returns the action name used in policy documents of the action type
+func PolicyActionName(action permTypes.ActionType) string {
+	for name, a := range policyActions {
+		if a == action {
+			return PolicyActionPrefix + name
+		}
+	}
+	return action.String()
+}
+
+// PolicyBuilder builds the policy document in a fluent way, for example:
+//
+//	doc, err := types.NewPolicyBuilder().
+//		Allow(permTypes.ACTION_GET_OBJECT).ToAccount(addr).OnObject(bucketName, "photos/*").
+//		Deny(permTypes.ACTION_DELETE_BUCKET).ToGroup(groupId).OnBucket(bucketName).
+//		Build()
+//
+// Allow and Deny start a new statement, the other methods apply to the current statement.
+type PolicyBuilder struct {
+	doc     PolicyDocument
+	current int // index of the current statement, -1 if no statement started
+	err     error
+}
+
+// NewPolicyBuilder returns an empty policy builder
+func NewPolicyBuilder() *PolicyBuilder {
+	return &PolicyBuilder{doc: PolicyDocument{Version: PolicyDocumentVersion}, current: -1}
+}
+
+// Allow starts a new statement which allows the actions
+func (b *PolicyBuilder) Allow(actions ...permTypes.ActionType) *PolicyBuilder {
+	return b.newStatement(PolicyEffectAllow, actions)
+}
+
+// Deny starts a new statement which denies the actions
+func (b *PolicyBuilder) Deny(actions ...permTypes.ActionType) *PolicyBuilder {
+	return b.newStatement(PolicyEffectDeny, actions)
+}
+
+// WithSid sets the statement id of the current statement
+func (b *PolicyBuilder) WithSid(sid string) *PolicyBuilder {
+	if b.checkStatement("WithSid") {
+		b.doc.Statement[b.current].Sid = sid
+	}
+	return b
+}
+
+// ToAccount adds the accounts to the principals of the current statement
+// accountAddrs indicates the HEX-encoded string list of the account addresses
+func (b *PolicyBuilder) ToAccount(accountAddrs ...string) *PolicyBuilder {
+	if b.checkStatement("ToAccount") {
+		b.doc.Statement[b.current].Principal.Account = append(b.doc.Statement[b.current].Principal.Account, accountAddrs...)
+	}
+	return b
+}
+
+// ToGroup adds the groups to the principals of the current statement
+func (b *PolicyBuilder) ToGroup(groupIds ...uint64) *PolicyBuilder {
+	if b.checkStatement("ToGroup") {
+		b.doc.Statement[b.current].Principal.Group = append(b.doc.Statement[b.current].Principal.Group, groupIds...)
+	}
+	return b
+}
+
+// OnBucket adds the bucket to the resources of the current statement
+func (b *PolicyBuilder) OnBucket(bucketName string) *PolicyBuilder {
+	return b.OnResource(gnfdTypes.NewBucketGRN(bucketName).String())
+}
+
+// OnObject adds the object to the resources of the current statement, the objectName supports the wildcards "*" and "?",
+// "photos/*" matches all the objects whose names start with "photos/"
+func (b *PolicyBuilder) OnObject(bucketName, objectName string) *PolicyBuilder {
+	return b.OnResource(gnfdTypes.NewObjectGRN(bucketName, objectName).String())
+}
+
+// OnGroup adds the group to the resources of the current statement
+// groupOwnerAddr indicates the HEX-encoded string of the group owner address
+func (b *PolicyBuilder) OnGroup(groupOwnerAddr, groupName string) *PolicyBuilder {
+	owner, err := sdk.AccAddressFromHexUnsafe(groupOwnerAddr)
+	if err != nil {
+		b.setErr(err)
+		return b
+	}
+	return b.OnResource(gnfdTypes.NewGroupGRN(owner, groupName).String())
+}
+
+// OnResource adds the GRN of the resource to the resources of the current statement
+func (b *PolicyBuilder) OnResource(grn string) *PolicyBuilder {
+	if b.checkStatement("OnResource") {
+		b.doc.Statement[b.current].Resource = append(b.doc.Statement[b.current].Resource, grn)
+	}
+	return b
+}
+
+// ExpireAt sets the expiration time of the current statement
+func (b *PolicyBuilder) ExpireAt(expireTime time.Time) *PolicyBuilder {
+	if b.checkStatement("ExpireAt") {
+		b.doc.Statement[b.current].ExpirationTime = &expireTime
+	}
+	return b
+}
+
+// LimitSize sets the total data size which is allowed to create of the current statement
+func (b *PolicyBuilder) LimitSize(size uint64) *PolicyBuilder {
+	if b.checkStatement("LimitSize") {
+		b.doc.Statement[b.current].LimitSize = size
+	}
+	return b
+}
+
+// PolicyExpireAt sets the expiration time of the whole policy
+func (b *PolicyBuilder) PolicyExpireAt(expireTime time.Time) *PolicyBuilder {
+	b.doc.ExpirationTime = &expireTime
+	return b
+}
+
+// Build returns the validated policy document or the first error met while building
+func (b *PolicyBuilder) Build() (*PolicyDocument, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+
+	doc := b.doc
+	doc.Statement = append([]PolicyStatement(nil), b.doc.Statement...)
+	if err := doc.Validate(); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+func (b *PolicyBuilder) newStatement(effect string, actions []permTypes.ActionType) *PolicyBuilder {
+	statement := PolicyStatement{Effect: effect}
+	for _, action := range actions {
+		statement.Action = append(statement.Action, PolicyActionName(action))
+	}
+
+	b.doc.Statement = append(b.doc.Statement, statement)
+	b.current = len(b.doc.Statement) - 1
+	return b
+}
+
+func (b *PolicyBuilder) checkStatement(method string) bool {
+	if b.current < 0 {
+		b.setErr(fmt.Errorf("%s called before the statement is started, call Allow or Deny first", method))
+		return false
+	}
+	return true
+}
+
+func (b *PolicyBuilder) setErr(err error) {
+	if b.err == nil {
+		b.err = err
+	}
+}
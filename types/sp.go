@@ -0,0 +1,65 @@
+package types
+
+import (
+	"fmt"
+	"time"
+
+	spTypes "github.com/bnb-chain/greenfield/x/sp/types"
+)
+
+// SPHealth indicates the chain status of the storage provider and the results of the recent probes
+type SPHealth struct {
+	OperatorAddress string
+	Endpoint        string
+	// Status indicates the status of the SP on chain, such as in service, jailed or graceful exiting
+	Status spTypes.Status
+	// Healthy indicates the SP can serve the requests, it is false if the SP is jailed or out of service on chain,
+	// or the recent probes failed consecutively for the failure threshold
+	Healthy bool
+
+	LastCheckTime time.Time
+	// LastError indicates the error of the last probe, it is empty if the last probe succeeded
+	LastError           string
+	ConsecutiveFailures int
+
+	// Probes and Failures indicate the number of the probes and the failed ones in the window
+	Probes   int
+	Failures int
+	// Availability indicates the ratio of the successful probes in the window
+	Availability float64
+	// LatencyP50, LatencyP90 and LatencyP99 indicate the latency percentiles of the successful probes in the window
+	LatencyP50 time.Duration
+	LatencyP90 time.Duration
+	LatencyP99 time.Duration
+}
+
+// String returns the readable summary of the SP health
+func (h SPHealth) String() string {
+	state := "healthy"
+	if !h.Healthy {
+		state = "unhealthy"
+	}
+	summary := fmt.Sprintf("%s (%s) %s, %s, availability %.2f%% of %d probes, latency p50 %s p90 %s p99 %s",
+		h.OperatorAddress, h.Endpoint, state, h.Status.String(), h.Availability*100, h.Probes,
+		h.LatencyP50, h.LatencyP90, h.LatencyP99)
+	if h.LastError != "" {
+		summary += ", last error: " + h.LastError
+	}
+	return summary
+}
+
+// StoragePriceChange indicates the read price or the store price of the SP is updated from the old price to the new price
+type StoragePriceChange struct {
+	SPAddress         string
+	Old               *spTypes.SpStoragePrice
+	New               *spTypes.SpStoragePrice
+	ReadPriceChanged  bool
+	StorePriceChanged bool
+	DetectTime        time.Time
+}
+
+// String returns the readable summary of the price change
+func (c StoragePriceChange) String() string {
+	return fmt.Sprintf("SP %s read price %s -> %s, store price %s -> %s at %s", c.SPAddress, c.Old.ReadPrice, c.New.ReadPrice,
+		c.Old.StorePrice, c.New.StorePrice, time.Unix(c.New.UpdateTimeSec, 0).UTC().Format(time.RFC3339))
+}
@@ -13,7 +13,14 @@ type (
 type ObjectStat struct {
 	ObjectName  string
 	ContentType string
-	Size        int64
+	// Size indicates the size of the returned payload
+	Size int64
+	// Codec indicates the compression codec of the stored payload, it is empty if the object is not compressed
+	Codec string
+	// StoredSize indicates the size of the payload stored in SP and LogicalSize indicates the size after decompression,
+	// they are the same if the object is not compressed
+	StoredSize  int64
+	LogicalSize int64
 }
 
 // ChallengeInfo indicates the challenge object info
@@ -31,3 +38,25 @@ type ChallengeResult struct {
 	IntegrityHash string
 	PiecesHash    []string
 }
+
+// ChallengeVerdict indicates the result of verifying the challenge result against the checksum on chain,
+// the hashes are hex encoded
+type ChallengeVerdict struct {
+	ObjectId        string
+	PieceIndex      int
+	RedundancyIndex int
+
+	// PieceHashMatched indicates the hash of the piece data equals the piece hash at the piece index
+	PieceHashMatched bool
+	// IntegrityHashMatched indicates the integrity hash recomputed from the piece hashes equals the returned integrity hash
+	IntegrityHashMatched bool
+	// ChecksumMatched indicates the returned integrity hash equals the checksum on chain of the redundancy index
+	ChecksumMatched bool
+	// Valid indicates all the checks passed, Reason indicates the first failed check if it is not valid
+	Valid  bool
+	Reason string
+
+	ComputedPieceHash     string
+	ComputedIntegrityHash string
+	ExpectedChecksum      string
+}